@@ -13,50 +13,94 @@ import (
 	"golang.org/x/text/language"
 )
 
-func (o *adapter) adapt(types []typeInfo, typeA string, fieldA string, typeB string, groupB string, ref bool) (string, error) {
-	infoA := getType(types, typeA)
-	if infoA == nil {
-		return "", fmt.Errorf(TypeIsMissingF, typeA)
-	}
+func (o *adapter) adapt(types []typeInfo, fieldA field, itemB *item, ref bool) (string, error) {
+	typeB := getTypeId(itemB)
 	infoB := getType(types, typeB)
 	if infoB == nil {
 		return "", fmt.Errorf(TypeIsMissingF, typeB)
 	}
-	fieldId := ""
-	for _, v := range infoA.Fields {
-		if v.FieldName == fieldA {
-			fieldId = v.Id
-			break
-		}
-	}
-	if fieldId == "" {
-		return "", fmt.Errorf(FieldIsMissingF, fieldA, typeA)
-	}
-	fieldInfo := getType(types, fieldId)
+	fieldInfo := getType(types, fieldA.Id)
 	if fieldInfo == nil {
-		return "", fmt.Errorf(TypeIsMissingF, fieldId)
+		return "", fmt.Errorf(TypeIsMissingF, fieldA.Id)
 	}
 	// create a new struct
-	name := ""
 	nameA := fmt.Sprintf("%s%s", cases.Title(language.English, cases.NoLower).String(filepath.Base(fieldInfo.PkgPath)), fieldInfo.Name)
-	nameB := fmt.Sprintf("%s%s", cases.Title(language.English, cases.NoLower).String(filepath.Base(infoB.PkgPath)), infoB.Name)
-	if groupB == "" {
-		name = fmt.Sprintf("%s%s%s", nameB, nameA, GenAdapterSufix)
-	} else {
-		name = fmt.Sprintf("%s%s%s%s%s", groupB, GenGroupPrefix, nameB, nameA, GenAdapterSufix)
-	}
+	nameB := strings.TrimPrefix(getFuncName(itemB, false), GenNamePrefix)
+	name := fmt.Sprintf("%s%s%s", nameB, nameA, GenAdapterSufix)
 	funcName := GenNamePrefix + name
 	if ref {
 		funcName = funcName + GenRefSufix
 	}
 	// if the adapter exists then return it
-	if o.code != nil && o.code[name] != nil {
+	if o.code != nil && o.code[funcName] != nil {
 		return funcName, nil
 	}
+	// the adapter of the shared item embeds a pointer to keep the single instance,
+	// the adapter of the component embeds a pointer to the instance which is started and stopped
+	embed := ""
+	if lc := getLifecycle(types, typeB); itemB.singleton || (o.lifecycle && lc != nil && lc.pointer) {
+		embed = "*"
+	}
+	// the adapter type is generated once for the value and the reference
+	if o.code == nil || o.code[name] == nil {
+		if err := o.adaptType(name, embed, fieldInfo, infoB); err != nil {
+			return "", err
+		}
+	}
+	code := []string{}
+	// generate the "Use" function, it returns the error of the fallible item
+	result := name
+	if ref {
+		result = "*" + name
+	}
+	if itemB.fallible {
+		result = fmt.Sprintf("(%s, error)", result)
+	}
+	code = append(code, fmt.Sprintf("func %s() %s {\n", funcName, result))
+	if ref {
+		code = append(code, fmt.Sprintf("\tv := &%s{}\n", name))
+	} else {
+		code = append(code, fmt.Sprintf("\tv := %s{}\n", name))
+	}
+	// the adapter of the shared item embeds the single instance kept by the reference
+	useName := getFuncName(itemB, ref || embed != "")
+	switch {
+	case itemB.fallible:
+		code = append(code, fmt.Sprintf("\tb, err := %s()\n", useName))
+		code = append(code, "\tif err != nil {\n")
+		code = append(code, "\t\treturn v, err\n")
+		code = append(code, "\t}\n")
+		if embed == "" && ref {
+			code = append(code, fmt.Sprintf("\tv.%s = *b\n", infoB.Name))
+		} else {
+			code = append(code, fmt.Sprintf("\tv.%s = b\n", infoB.Name))
+		}
+	case embed == "" && ref:
+		code = append(code, fmt.Sprintf("\tv.%s = *%s()\n", infoB.Name, useName))
+	default:
+		code = append(code, fmt.Sprintf("\tv.%s = %s()\n", infoB.Name, useName))
+	}
+	if itemB.fallible {
+		code = append(code, "\treturn v, nil\n")
+	} else {
+		code = append(code, "\treturn v\n")
+	}
+	code = append(code, "}\n\n")
+	// the reference to the adapter of the shared item is shared too
+	if itemB.singleton && ref {
+		code = getSharedCode(o.imports, funcName, "*"+name, itemB.fallible, code)
+	}
+	o.keep(funcName, code)
+	return funcName, nil
+}
+
+// adaptType generates the adapter type with methods of the field type
+func (o *adapter) adaptType(name, embed string, fieldInfo, infoB *typeInfo) error {
+	typeB := infoB.Id
 	alias := string(appendImport(o.imports, infoB.PkgPath))
 	code := []string{}
 	code = append(code, fmt.Sprintf("type %s struct {\n", name))
-	code = append(code, fmt.Sprintf("\t%s.%s\n}\n\n", alias, infoB.Name))
+	code = append(code, fmt.Sprintf("\t%s%s.%s\n}\n\n", embed, alias, infoB.Name))
 	// check methods
 	var fA field
 	var fB field
@@ -65,7 +109,6 @@ func (o *adapter) adapt(types []typeInfo, typeA string, fieldA string, typeB str
 	var iP int
 	var countA int
 	var countB int
-	var field string
 	var fields []string
 	var incompatible bool
 	for _, v := range fieldInfo.Methods {
@@ -85,13 +128,13 @@ func (o *adapter) adapt(types []typeInfo, typeA string, fieldA string, typeB str
 					iB++
 				}
 				if (countA - iA) != (countB - iB) {
-					return "", fmt.Errorf(WrongNumberOfInputParamsF, fieldA, typeA, typeB)
+					return fmt.Errorf(WrongNumberOfInputParamsF, v.Name, fieldInfo.Id, typeB)
 				}
-				incompatible = false
+				incompatible = v.Variadic != x.Variadic
 				for i := iA; i < countA; i++ {
 					fA = v.In[i]
 					fB = x.In[iB]
-					if fA.Kind != fB.Kind || fA.Id != fB.Id {
+					if !isSameType(&fA, &fB) {
 						incompatible = true
 						break
 					}
@@ -99,11 +142,11 @@ func (o *adapter) adapt(types []typeInfo, typeA string, fieldA string, typeB str
 				}
 				// check output parameters
 				if len(x.Out) != len(v.Out) {
-					return "", fmt.Errorf(WrongNumberOfOutputParamsF, fieldA, typeA, typeB)
+					return fmt.Errorf(WrongNumberOfOutputParamsF, v.Name, fieldInfo.Id, typeB)
 				}
 				for i, p := range x.Out {
 					fA = v.Out[i]
-					if p.Kind != fA.Kind || p.Id != fA.Id {
+					if !isSameType(&p, &fA) {
 						incompatible = true
 						break
 					}
@@ -120,12 +163,14 @@ func (o *adapter) adapt(types []typeInfo, typeA string, fieldA string, typeB str
 					fields = []string{}
 					for i := iA; i < countA; i++ {
 						fA = v.In[i]
-						alias = string(appendImport(o.imports, fA.PkgPath))
-						field = fmt.Sprintf("%s.%s", alias, fA.TypeName)
-						if field[0:1] == "." {
-							field = field[1:]
+						param, err := getTypeDefine(o.imports, &fA)
+						if err != nil {
+							return err
+						}
+						if v.Variadic && i == countA-1 {
+							param = "..." + strings.TrimPrefix(param, "[]")
 						}
-						fields = append(fields, fmt.Sprintf("a%d %s", iP, field))
+						fields = append(fields, fmt.Sprintf("a%d %s", iP, param))
 						iP++
 					}
 					iP = 1
@@ -133,12 +178,11 @@ func (o *adapter) adapt(types []typeInfo, typeA string, fieldA string, typeB str
 					fields = []string{}
 					for i := range v.Out {
 						fA = v.Out[i]
-						alias = string(appendImport(o.imports, fA.PkgPath))
-						field = fmt.Sprintf("%s.%s", alias, fA.TypeName)
-						if field[0:1] == "." {
-							field = field[1:]
+						param, err := getTypeDefine(o.imports, &fA)
+						if err != nil {
+							return err
 						}
-						fields = append(fields, fmt.Sprintf("r%d %s", iP, field))
+						fields = append(fields, fmt.Sprintf("r%d %s", iP, param))
 						iP++
 					}
 					if len(fields) > 0 {
@@ -147,7 +191,7 @@ func (o *adapter) adapt(types []typeInfo, typeA string, fieldA string, typeB str
 					code = append(code, " {\n")
 					rcode, err := o.resolveMethod(infoB.Name, x, v)
 					if err != nil {
-						return "", err
+						return err
 					}
 					code = append(code, rcode...)
 					code = append(code, "}\n\n")
@@ -156,62 +200,117 @@ func (o *adapter) adapt(types []typeInfo, typeA string, fieldA string, typeB str
 			}
 		}
 		if !found {
-			return "", fmt.Errorf(MethodIsMissingF, v.Name, infoB.Id)
+			return fmt.Errorf(MethodIsMissingF, v.Name, infoB.Id)
 		}
 	}
-	// generate the "Use" function
-	if ref {
-		code = append(code, fmt.Sprintf("func %s() *%s {\n", funcName, name))
-		code = append(code, fmt.Sprintf("\tv := &%s{}\n", name))
-	} else {
-		code = append(code, fmt.Sprintf("func %s() %s {\n", funcName, name))
-		code = append(code, fmt.Sprintf("\tv := %s{}\n", name))
+	o.keep(name, code)
+	return nil
+}
+
+// adaptEnv returns a name of function which converts a value of the environment variable to the field type,
+// the number of bits of the result and the name of the result type
+func (o *adapter) adaptEnv(f *field) (string, int, string, error) {
+	kind := ""
+	bits := 0
+	result := ""
+	switch f.Kind {
+	case reflect.String:
+		kind, result = "String", "string"
+	case reflect.Interface:
+		if f.Id == "." {
+			// it is type of interface{} and the result does not require a conversion
+			kind = "String"
+		}
+	case reflect.Bool:
+		kind, result = "Bool", "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		kind, result = "Int", "int64"
+		if f.Id == "time.Duration" {
+			kind, result = "Duration", f.TypeName
+		} else if f.Kind != reflect.Int {
+			bits, _ = strconv.Atoi(strings.TrimPrefix(f.Kind.String(), "int"))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		kind, result = "Uint", "uint64"
+		if f.Kind == reflect.Uintptr {
+			bits = 64
+		} else if f.Kind != reflect.Uint {
+			bits, _ = strconv.Atoi(strings.TrimPrefix(f.Kind.String(), "uint"))
+		}
+	case reflect.Float32, reflect.Float64:
+		kind, result = "Float", "float64"
+		bits, _ = strconv.Atoi(strings.TrimPrefix(f.Kind.String(), "float"))
+	}
+	if kind == "" {
+		return "", 0, "", fmt.Errorf(TypeDoesNotSupportedF, f.Kind)
+	}
+	funcName := GenEnvPrefix + kind
+	// if the function exists then return it
+	if o.code != nil && o.code[funcName] != nil {
+		return funcName, bits, result, nil
 	}
-	if groupB == "" {
-		code = append(code, fmt.Sprintf("\tv.%s = *%s%s%s()\n", infoB.Name, GenNamePrefix, nameB, GenRefSufix))
+	osAlias := string(appendImport(o.imports, "os"))
+	code := []string{}
+	switch kind {
+	case "String", "Bool":
+		code = append(code, fmt.Sprintf("func %s(name string, value string) %s {\n", funcName, strings.ToLower(kind)))
+	case "Duration":
+		code = append(code, fmt.Sprintf("func %s(name string, value string) %s.Duration {\n", funcName, appendImport(o.imports, "time")))
+	default:
+		code = append(code, fmt.Sprintf("func %s(name string, value string, bits int) %s {\n", funcName, result))
+	}
+	code = append(code, fmt.Sprintf("\tif v, found := %s.LookupEnv(name); found {\n", osAlias))
+	code = append(code, "\t\tvalue = v\n")
+	code = append(code, "\t}\n")
+	if kind == "String" {
+		code = append(code, "\treturn value\n")
 	} else {
-		code = append(code, fmt.Sprintf("\tv.%s = *%s%s%s%s%s()\n", infoB.Name, GenNamePrefix, groupB, GenGroupPrefix, nameB, GenRefSufix))
+		switch kind {
+		case "Duration":
+			code = append(code, fmt.Sprintf("\tr, err := %s.ParseDuration(value)\n", appendImport(o.imports, "time")))
+		case "Bool":
+			code = append(code, fmt.Sprintf("\tr, err := %s.ParseBool(value)\n", appendImport(o.imports, "strconv")))
+		case "Float":
+			code = append(code, fmt.Sprintf("\tr, err := %s.ParseFloat(value, bits)\n", appendImport(o.imports, "strconv")))
+		default:
+			code = append(code, fmt.Sprintf("\tr, err := %s.Parse%s(value, 0, bits)\n", appendImport(o.imports, "strconv"), kind))
+		}
+		code = append(code, "\tif err != nil {\n")
+		code = append(code, fmt.Sprintf("\t\t%s.Fprintf(%s.Stderr, \"cannot use the value of \\\"%%s\\\" environment variable: %%s\\n\", name, err)\n", appendImport(o.imports, "fmt"), osAlias))
+		code = append(code, fmt.Sprintf("\t\t%s.Exit(1)\n", osAlias))
+		code = append(code, "\t}\n")
+		code = append(code, "\treturn r\n")
 	}
-	code = append(code, "\treturn v\n")
 	code = append(code, "}\n\n")
-	// keep a new code
+	o.keep(funcName, code)
+	return funcName, bits, result, nil
+}
+
+// keep keeps a new code, the order of names is the order of generation
+func (o *adapter) keep(name string, code []string) {
 	if o.code == nil {
 		o.code = map[string][]string{}
 	}
+	if o.code[name] == nil {
+		o.names = append(o.names, name)
+	}
 	o.code[name] = append(o.code[name], code...)
-	return funcName, nil
 }
 
-func (o *adapter) areTypesCompatible(types []typeInfo, typeA string, fieldA string, typeB string) (bool, error) {
+func (o *adapter) areTypesCompatible(types []typeInfo, fieldA field, typeB string) (bool, error) {
 	// get input types
-	infoA := getType(types, typeA)
-	if infoA == nil {
-		return false, fmt.Errorf(TypeIsMissingF, typeA)
-	}
 	infoB := getType(types, typeB)
 	if infoB == nil {
 		return false, fmt.Errorf(TypeIsMissingF, typeB)
 	}
-	fieldId := ""
-	var fieldOrigInfo field
-	for _, v := range infoA.Fields {
-		if v.FieldName == fieldA {
-			fieldId = v.Id
-			fieldOrigInfo = v
-			break
-		}
-	}
-	if fieldId == "" {
-		return false, fmt.Errorf(FieldIsMissingF, fieldA, typeA)
-	}
-	fieldInfo := getType(types, fieldId)
+	fieldInfo := getType(types, fieldA.Id)
 	if fieldInfo == nil {
-		if fieldOrigInfo.Id == "." && fieldOrigInfo.PkgPath == "" && fieldOrigInfo.TypeName == "" &&
-			(fieldOrigInfo.Kind == reflect.Interface || fieldOrigInfo.Kind == reflect.Pointer) {
+		if fieldA.Id == "." && fieldA.PkgPath == "" && fieldA.TypeName == "" &&
+			(fieldA.Kind == reflect.Interface || fieldA.Kind == reflect.Pointer) {
 			// it is type of interface{}
 			return true, nil
 		} else {
-			return false, fmt.Errorf(TypeIsMissingFieldIdF, fieldId)
+			return false, fmt.Errorf(TypeIsMissingFieldIdF, fieldA.Id)
 		}
 	}
 	// check compatibility of input types
@@ -244,23 +343,26 @@ func (o *adapter) areTypesCompatible(types []typeInfo, typeA string, fieldA stri
 					iB++
 				}
 				if (countA - iA) != (countB - iB) {
-					return false, fmt.Errorf(WrongNumberOfInputParamsF, fieldA, typeA, typeB)
+					return false, fmt.Errorf(WrongNumberOfInputParamsF, v.Name, fieldInfo.Id, typeB)
+				}
+				if v.Variadic != x.Variadic {
+					return false, nil
 				}
 				for i := iA; i < countA; i++ {
 					fA = v.In[i]
 					fB = x.In[iB]
-					if fA.Kind != fB.Kind || fA.Id != fB.Id {
+					if !isSameType(&fA, &fB) {
 						return false, nil
 					}
 					iB++
 				}
 				// check output parameters
 				if len(x.Out) != len(v.Out) {
-					return false, fmt.Errorf(WrongNumberOfOutputParamsF, fieldA, typeA, typeB)
+					return false, fmt.Errorf(WrongNumberOfOutputParamsF, v.Name, fieldInfo.Id, typeB)
 				}
 				for i, p := range x.Out {
 					fA = v.Out[i]
-					if p.Kind != fA.Kind || p.Id != fA.Id {
+					if !isSameType(&p, &fA) {
 						return false, nil
 					}
 				}
@@ -293,7 +395,7 @@ func (o *adapter) resolveMethod(obj string, m1 method, m2 method) ([]string, err
 	if countB > 0 && m2.In[0].Id == "." && m2.In[0].Kind == reflect.Ptr {
 		iB++
 	}
-	if (countA - iA) != (countB - iB) {
+	if (countA-iA) != (countB-iB) || m1.Variadic != m2.Variadic {
 		return nil, fmt.Errorf(WrongNumberOfInputParamsForMethodsF, m1.Name, m2.Name)
 	}
 	// process input parameters
@@ -306,6 +408,10 @@ func (o *adapter) resolveMethod(obj string, m1 method, m2 method) ([]string, err
 		if err != nil {
 			return nil, err
 		}
+		// the variadic parameter is passed as a slice
+		if m1.Variadic && i == countA-1 {
+			name += "..."
+		}
 		inputs = append(inputs, name)
 		inCode = append(inCode, code...)
 		iB++
@@ -345,7 +451,7 @@ func (o *adapter) equals(l1 []field, l2 []field) bool {
 	var f2 field
 	for i, f1 := range l1 {
 		f2 = l2[i]
-		if f1.Kind != f2.Kind || f1.Id != f2.Id {
+		if !isSameType(&f1, &f2) {
 			return false
 		}
 	}
@@ -353,7 +459,7 @@ func (o *adapter) equals(l1 []field, l2 []field) bool {
 }
 
 func (o *adapter) resolveParameter(in bool, name1 string, f1 field, name2 string, f2 field) (string, []string, error) {
-	if f1.Kind == f2.Kind && f1.Id == f2.Id {
+	if isSameType(&f1, &f2) {
 		if in {
 			return name1, nil, nil
 		} else {
@@ -367,5 +473,5 @@ func (o *adapter) resolveParameter(in bool, name1 string, f1 field, name2 string
 			return name1, []string{fmt.Sprintf("\t%s = %s.(%s.%s)\n", name2, name1, alias, f2.FieldName)}, nil
 		}
 	}
-	return "", nil, fmt.Errorf(ParamsDoesNotSupportedF, f1.TypeName, f2.TypeName)
+	return "", nil, fmt.Errorf(ParamsDoesNotSupportedF, getTypeDesc(&f1), getTypeDesc(&f2))
 }
@@ -0,0 +1,290 @@
+// Copyright 2022 Vitalii Noha vitalii.noga@gmail.com. All rights reserved.
+
+package sgo
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// newTypeCache returns the cache of the module which contains the working folder,
+// the cache is disabled if the module is missing
+func newTypeCache(root, wd, version string) *typeCache {
+	dir, modDir, err := getCacheDir(root, wd)
+	if err != nil || dir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(modDir, moduleFileName))
+	if err != nil {
+		return nil
+	}
+	mod, err := modfile.Parse(moduleFileName, data, nil)
+	if err != nil || mod.Module == nil {
+		return nil
+	}
+	c := &typeCache{
+		dir:     filepath.Join(dir, cacheVersion),
+		modPath: mod.Module.Mod.Path,
+		modDir:  modDir,
+		version: version,
+		mods:    map[string]string{},
+		keys:    map[string]string{},
+		entries: map[string]*cacheEntry{},
+		changed: map[string]bool{},
+	}
+	sums := readChecksums(filepath.Join(modDir, checksumFileName))
+	for _, r := range mod.Require {
+		path, version := r.Mod.Path, r.Mod.Version
+		local := false
+		for _, x := range mod.Replace {
+			if x.Old.Path == path && (x.Old.Version == "" || x.Old.Version == version) {
+				// the replaced module is local if the version is missing
+				path, version, local = x.New.Path, x.New.Version, x.New.Version == ""
+			}
+		}
+		if !local {
+			c.mods[r.Mod.Path] = strings.TrimSpace(path + "@" + version + " " + sums[path+" "+version])
+		}
+	}
+	return c
+}
+
+// get returns the cached details of the type if the type and all packages it depends on are not changed
+func (c *typeCache) get(x typeInfo) (typeInfo, bool) {
+	if c == nil {
+		return typeInfo{}, false
+	}
+	key := c.key(x.PkgPath)
+	if key == "" {
+		return typeInfo{}, false
+	}
+	entry := c.entry(x.PkgPath)
+	if entry.Key != key {
+		return typeInfo{}, false
+	}
+	t, found := entry.Types[x.Id]
+	if !found {
+		return typeInfo{}, false
+	}
+	for path, key := range t.Deps {
+		if c.key(path) != key {
+			return typeInfo{}, false
+		}
+	}
+	return t.Info, true
+}
+
+// put keeps the details of the type if the keys of all packages it depends on are known
+func (c *typeCache) put(x typeInfo, info typeInfo) {
+	if c == nil {
+		return
+	}
+	key := c.key(x.PkgPath)
+	if key == "" {
+		return
+	}
+	paths := map[string]bool{}
+	addTypeDeps(paths, &info)
+	// the type arguments of an instantiated generic type
+	getTypeExpr(x.PkgPath+"."+x.Name, func(path string) string {
+		paths[path] = true
+		return "_"
+	})
+	delete(paths, x.PkgPath)
+	deps := map[string]string{}
+	for path := range paths {
+		if deps[path] = c.key(path); deps[path] == "" {
+			return
+		}
+	}
+	entry := c.entry(x.PkgPath)
+	if entry.Key != key {
+		entry.Key = key
+		entry.Types = map[string]cachedType{}
+	}
+	entry.Types[x.Id] = cachedType{Info: info, Deps: deps}
+	c.changed[x.PkgPath] = true
+}
+
+// save writes all changed entries, the cache is optional so the errors are skipped
+func (c *typeCache) save() {
+	if c == nil || len(c.changed) == 0 {
+		return
+	}
+	if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
+		return
+	}
+	for path := range c.changed {
+		f, err := os.CreateTemp(c.dir, "*.tmp")
+		if err != nil {
+			return
+		}
+		err = gob.NewEncoder(f).Encode(c.entries[path])
+		f.Close()
+		if err == nil {
+			err = os.Rename(f.Name(), c.fileName(path))
+		}
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}
+	c.changed = map[string]bool{}
+}
+
+// entry returns the cached entry of the package or an empty one
+func (c *typeCache) entry(path string) *cacheEntry {
+	if entry, found := c.entries[path]; found {
+		return entry
+	}
+	entry := &cacheEntry{Types: map[string]cachedType{}}
+	if f, err := os.Open(c.fileName(path)); err == nil {
+		if gob.NewDecoder(f).Decode(entry) != nil {
+			entry = &cacheEntry{Types: map[string]cachedType{}}
+		}
+		f.Close()
+	}
+	c.entries[path] = entry
+	return entry
+}
+
+// key returns the key of the package which is changed together with the package,
+// the empty key is returned if the package can not be cached
+func (c *typeCache) key(path string) string {
+	if key, found := c.keys[path]; found {
+		return key
+	}
+	key := ""
+	switch {
+	case path == c.modPath || strings.HasPrefix(path, c.modPath+"/"):
+		// the packages of the main module are checked by the content of files
+		key = hashFiles(filepath.Join(c.modDir, strings.TrimPrefix(path, c.modPath)))
+	case !strings.Contains(strings.Split(path, "/")[0], "."):
+		key = c.version
+	default:
+		// the packages of other modules are checked by the selected version of the module
+		mod := ""
+		for x := range c.mods {
+			if (path == x || strings.HasPrefix(path, x+"/")) && len(x) > len(mod) {
+				mod = x
+			}
+		}
+		if mod != "" {
+			key = c.mods[mod]
+		}
+	}
+	c.keys[path] = key
+	return key
+}
+
+func (c *typeCache) fileName(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".gob")
+}
+
+// getCacheDir returns the cache folder in the root folder and the root folder of the module which contains the folder
+func getCacheDir(root, path string) (string, string, error) {
+	for {
+		if _, err := os.Stat(filepath.Join(path, moduleFileName)); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return "", "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", "", nil
+		}
+		path = parent
+	}
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(root, hex.EncodeToString(sum[:8])), path, nil
+}
+
+// readChecksums returns the checksums of modules by the path and the version
+func readChecksums(filePath string) map[string]string {
+	res := map[string]string{}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return res
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		x := strings.Fields(scanner.Text())
+		// skip the checksums of module files
+		if len(x) == 3 && !strings.HasSuffix(x[1], "/"+moduleFileName) {
+			res[x[0]+" "+x[1]] = x[2]
+		}
+	}
+	return res
+}
+
+// hashFiles returns the hash of all Go files of the folder except tests
+func hashFiles(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	names := []string{}
+	for _, x := range entries {
+		if !x.IsDir() && strings.HasSuffix(x.Name(), ".go") && !strings.HasSuffix(x.Name(), "_test.go") {
+			names = append(names, x.Name())
+		}
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return ""
+		}
+		h.Write([]byte(name))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// addTypeDeps collects the packages of all types which are used by the type
+func addTypeDeps(paths map[string]bool, info *typeInfo) {
+	if info.PkgPath != "" {
+		paths[info.PkgPath] = true
+	}
+	for i := range info.Fields {
+		addFieldDeps(paths, &info.Fields[i])
+	}
+	for _, m := range info.Methods {
+		for i := range m.In {
+			addFieldDeps(paths, &m.In[i])
+		}
+		for i := range m.Out {
+			addFieldDeps(paths, &m.Out[i])
+		}
+	}
+	addFieldDeps(paths, info.Elem)
+	addFieldDeps(paths, info.Key)
+	addFieldDeps(paths, info.Value)
+}
+
+func addFieldDeps(paths map[string]bool, f *field) {
+	if f == nil {
+		return
+	}
+	if f.PkgPath != "" {
+		paths[f.PkgPath] = true
+	}
+	addFieldDeps(paths, f.Elem)
+	addFieldDeps(paths, f.Key)
+	for i := range f.In {
+		addFieldDeps(paths, &f.In[i])
+	}
+	for i := range f.Out {
+		addFieldDeps(paths, &f.Out[i])
+	}
+}
@@ -4,9 +4,15 @@ package sgo
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
+	"go/format"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/dastoori/higgs"
 )
@@ -15,6 +21,10 @@ func (g *Coder) Init(items map[string][][]string) {
 	g.items = items
 }
 
+// Generate generates the sources of the application,
+// if the "lifecycle" attribute of the application is "true" then the components which have
+// "Start(context.Context) error", "Stop(context.Context) error" or "Close() error" methods
+// are started in the order of creation and stopped in the reverse order by the application
 func (g *Coder) Generate(application string) error {
 	g.Logger.Info(fmt.Sprintf("generating \"%s\" application", application))
 	if err := checkApplication(application); err != nil {
@@ -25,18 +35,7 @@ func (g *Coder) Generate(application string) error {
 		return err
 	}
 	// create a hidden folder as wd
-	wd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
-	wd = filepath.Join(wd, application, workingFolderName)
-	if _, err = os.Stat(wd); err == nil {
-		os.RemoveAll(wd)
-	}
-	if err = os.MkdirAll(wd, os.ModePerm); err != nil {
-		return err
-	}
-	wd, err = higgs.Hide(wd)
+	wd, err := g.workingDir(application)
 	if err != nil {
 		return err
 	}
@@ -81,6 +80,12 @@ func (g *Coder) Clean(application string) error {
 					if _, err := os.Stat(filePath); err == nil {
 						os.Remove(filePath)
 					}
+					// remove the cached type details of the module
+					if root, err := g.cacheRoot(); err == nil {
+						if cacheDir, _, err := getCacheDir(root, folderPath); err == nil && cacheDir != "" {
+							os.RemoveAll(cacheDir)
+						}
+					}
 					// remove the application folder if it is empty
 					if empty, _ := isDirEmpty(folderPath); empty {
 						os.Remove(folderPath)
@@ -93,10 +98,246 @@ func (g *Coder) Clean(application string) error {
 	return nil
 }
 
+func (g *Coder) Graph(application, format string) (string, error) {
+	g.Logger.Info(fmt.Sprintf("exporting a graph of \"%s\" application", application))
+	if err := checkApplication(application); err != nil {
+		return "", err
+	}
+	switch format {
+	case GraphFormatDot, GraphFormatMermaid, GraphFormatJson:
+	default:
+		return "", fmt.Errorf(GraphFormatIsUnknownF, format)
+	}
+	entry, err := g.entryPoint(application)
+	if err != nil {
+		return "", err
+	}
+	// create a hidden folder as wd and remove the application folder if it is not used
+	wd, err := g.workingDir(application)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		os.RemoveAll(wd)
+		if empty, _ := isDirEmpty(filepath.Dir(wd)); empty {
+			os.Remove(filepath.Dir(wd))
+		}
+	}()
+	// resolve all dependencies
+	r := resolver{
+		application: application,
+		entryPoint:  entry,
+		items:       g.items,
+		profile:     g.profile(application),
+		autowire:    g.autowire(application),
+		cache:       g.typeCache(wd),
+	}
+	list, types, err := r.resolve(wd)
+	if err != nil {
+		return "", err
+	}
+	gr, err := getGraph(application, entry, r.profile, list, types)
+	if err != nil {
+		return "", err
+	}
+	switch format {
+	case GraphFormatDot:
+		return gr.dot(), nil
+	case GraphFormatMermaid:
+		return gr.mermaid(), nil
+	default:
+		return gr.json()
+	}
+}
+
+func (g *Coder) Analyze() (*Report, error) {
+	g.Logger.Info("analyzing all applications")
+	apps, err := readItem(appsItemName, g.items)
+	if err != nil {
+		return nil, err
+	}
+	// create a hidden folder as wd to check the types
+	wd, err := g.workingDir("")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(wd)
+	report := &Report{Unused: []string{}, Unresolved: []UnresolvedApp{}}
+	used := map[string]bool{appsItemName: true}
+	profiles := g.profiles()
+	cache := g.typeCache(wd)
+	// the packages are loaded once for all applications and profiles
+	loader := newTypeLoader(wd, cache)
+	defer loader.cache.save()
+	for _, app := range apps {
+		if len(app) == 0 {
+			continue
+		}
+		application := app[0]
+		used[application] = true
+		entry, err := g.entryPoint(application)
+		if err != nil {
+			g.Logger.Warn(fmt.Sprintf(AppIsUnresolvedF, application, err.Error()))
+			report.Unresolved = append(report.Unresolved, UnresolvedApp{Application: application, Error: err.Error()})
+			continue
+		}
+		// the items are resolved like the generator does including the undeclared struct items,
+		// the items of other profiles are resolved too because the profile can be selected on generation
+		selected := g.profile(application)
+		list := []string{selected}
+		for _, p := range profiles {
+			if p != selected {
+				list = append(list, p)
+			}
+		}
+		// the same error of the application is reported once for the selected profile
+		reported := map[string]bool{}
+		for _, p := range list {
+			r := resolver{
+				application: application,
+				entryPoint:  entry,
+				items:       g.items,
+				profile:     p,
+				autowire:    g.autowire(application),
+				used:        used,
+				cache:       cache,
+				loader:      loader,
+			}
+			if _, _, err = r.resolve(wd); err == nil || reported[err.Error()] {
+				continue
+			}
+			reported[err.Error()] = true
+			if p == "" {
+				g.Logger.Warn(fmt.Sprintf(AppIsUnresolvedF, application, err.Error()))
+			} else {
+				g.Logger.Warn(fmt.Sprintf(AppProfileIsUnresolvedF, application, p, err.Error()))
+			}
+			report.Unresolved = append(report.Unresolved, UnresolvedApp{
+				Application: application,
+				Entry:       entry,
+				Profile:     p,
+				Error:       err.Error(),
+			})
+		}
+	}
+	for name := range g.items {
+		if !used[name] {
+			report.Unused = append(report.Unused, name)
+		}
+	}
+	sort.Strings(report.Unused)
+	for _, name := range report.Unused {
+		g.Logger.Warn(fmt.Sprintf(ItemIsUnusedF, name))
+	}
+	return report, nil
+}
+
+// profiles returns the names of all groups of items which can be selected as a profile including the empty one
+func (g *Coder) profiles() []string {
+	found := map[string]bool{"": true}
+	for name := range g.items {
+		if end := strings.Index(name, "]"); strings.HasPrefix(name, "[") && end > 0 {
+			found[name[1:end]] = true
+		}
+	}
+	res := []string{}
+	for name := range found {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// cacheRoot returns the root folder of the cached type details
+func (g *Coder) cacheRoot() (string, error) {
+	if g.cacheDir != "" {
+		return g.cacheDir, nil
+	}
+	root, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, cacheFolderName), nil
+}
+
+// typeCache returns the cache of the type details of the module which contains the working folder,
+// it is nil if the cache is disabled
+func (g *Coder) typeCache(wd string) *typeCache {
+	root, err := g.cacheRoot()
+	if err != nil {
+		return nil
+	}
+	// the standard packages depend on the version of Go
+	if g.goVersion == "" {
+		cmd := exec.Command("go", "env", "GOVERSION")
+		cmd.Dir = wd
+		out, err := cmd.Output()
+		if err != nil {
+			return nil
+		}
+		g.goVersion = strings.TrimSpace(string(out))
+	}
+	return newTypeCache(root, wd, g.goVersion)
+}
+
 func (g *Coder) SetLogger(logger Logger) {
 	g.Logger = logger
 }
 
+func (g *Coder) workingDir(application string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	wd = filepath.Join(wd, application, workingFolderName)
+	if _, err = os.Stat(wd); err == nil {
+		os.RemoveAll(wd)
+	}
+	if err = os.MkdirAll(wd, os.ModePerm); err != nil {
+		return "", err
+	}
+	return higgs.Hide(wd)
+}
+
+func (g *Coder) profile(application string) string {
+	if g.Profile != "" {
+		return g.Profile
+	}
+	if profile := os.Getenv(profileEnvName); profile != "" {
+		return profile
+	}
+	if info, err := readItem(application, g.items); err == nil {
+		for _, i := range info {
+			if i[0] == profileAttrName && len(i) > 1 {
+				return i[1]
+			}
+		}
+	}
+	return ""
+}
+
+func (g *Coder) autowire(application string) bool {
+	if info, err := readItem(application, g.items); err == nil {
+		for _, i := range info {
+			if i[0] == autowireAttrName && len(i) > 1 {
+				return i[1] == "true"
+			}
+		}
+	}
+	return false
+}
+
+func (g *Coder) lifecycle(application string) bool {
+	if info, err := readItem(application, g.items); err == nil {
+		for _, i := range info {
+			if i[0] == lifecycleAttrName && len(i) > 1 {
+				return i[1] == "true"
+			}
+		}
+	}
+	return false
+}
+
 func (g *Coder) entryPoint(application string) (string, error) {
 	// read the apps item
 	apps, err := readItem(appsItemName, g.items)
@@ -163,42 +404,62 @@ func (g *Coder) generateAppFile(application string) error {
 func (g *Coder) generateDepsFile(application, entryPoint, wd string) error {
 	// check and get info about all dependencies
 	r := resolver{
-		application,
-		entryPoint,
-		g.items,
+		application: application,
+		entryPoint:  entryPoint,
+		items:       g.items,
+		profile:     g.profile(application),
+		autowire:    g.autowire(application),
+		cache:       g.typeCache(wd),
+	}
+	if r.profile != "" {
+		g.Logger.Info(fmt.Sprintf("using \"%s\" profile", r.profile))
 	}
 	list, types, err := r.resolve(wd)
 	if err != nil {
 		return err
 	}
-	code, imports, err := g.generateItems(entryPoint, list, types)
+	lifecycle := g.lifecycle(application)
+	code, imports, err := g.generateItems(entryPoint, list, types, lifecycle)
 	if err != nil {
 		return err
 	}
 	entry, found := list[entryPoint]
-	if found && entry.kind == itemKind.String {
-		imports["fmt"] = ""
-	}
-	// save dependencies to a file
-	pd, _ := os.Getwd()
-	root := filepath.Join(pd, application)
-	if _, err := os.Stat(root); os.IsNotExist(err) {
-		os.Mkdir(root, os.ModePerm)
-	}
-	file, err := os.Create(filepath.Join(root, depsFileName))
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-	defer writer.Flush()
+	// the components are started and stopped by the application only
+	components := lifecycle && found && (entry.kind == itemKind.Struct || entry.kind == itemKind.Inline) && g.hasComponents(list, types)
+	fmtAlias := alias("")
+	osAlias := alias("")
+	valueAlias := alias("")
+	if found && (entry.kind == itemKind.String || entry.kind == itemKind.Value || entry.fallible) || components {
+		fmtAlias = appendImport(imports, "fmt")
+	}
+	if found && entry.fallible || components {
+		osAlias = appendImport(imports, "os")
+	}
+	ctxAlias := alias("")
+	signalAlias := alias("")
+	syscallAlias := alias("")
+	syncAlias := alias("")
+	if components {
+		ctxAlias = appendImport(imports, "context")
+		signalAlias = appendImport(imports, "os/signal")
+		syscallAlias = appendImport(imports, "syscall")
+		syncAlias = appendImport(imports, "sync")
+	}
+	if found && entry.kind == itemKind.Value {
+		valueAlias = appendImport(imports, entry.path+entry.pkg)
+	}
+	writer := bytes.Buffer{}
 	writer.WriteString("package main\n\n")
-	// write the import section
+	// write the import section sorted by paths
 	if len(imports) > 0 {
+		paths := []string{}
+		for path := range imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
 		writer.WriteString("import (\n")
-		for path, alias := range imports {
-			if alias == "" {
+		for _, path := range paths {
+			if alias := imports[path]; alias == "" {
 				writer.WriteString(fmt.Sprintf("\t\"%s\"\n", path))
 			} else {
 				writer.WriteString(fmt.Sprintf("\t%s \"%s\"\n", alias, path))
@@ -212,49 +473,212 @@ func (g *Coder) generateDepsFile(application, entryPoint, wd string) error {
 		switch entry.kind {
 		case itemKind.Func:
 			writer.WriteString(fmt.Sprintf("\t%s.%s\n", entry.pkg, entry.name))
-		case itemKind.Struct:
-			funcName := fmt.Sprintf("\tapp := %s()\n", getFuncName(&entry, false))
-			writer.WriteString(funcName)
+		case itemKind.Struct, itemKind.Inline:
+			if entry.fallible {
+				// report the failing component and exit
+				writer.WriteString(fmt.Sprintf("\tapp, err := %s()\n", getFuncName(&entry, false)))
+				writer.WriteString("\tif err != nil {\n")
+				if components {
+					// the components created before the failure are released
+					writer.WriteString("\t\tstopComponents()\n")
+				}
+				writer.WriteString(fmt.Sprintf("\t\t%s.Fprintf(%s.Stderr, \"cannot create the application: %%s\\n\", err)\n", fmtAlias, osAlias))
+				writer.WriteString(fmt.Sprintf("\t\t%s.Exit(1)\n", osAlias))
+				writer.WriteString("\t}\n")
+			} else {
+				funcName := fmt.Sprintf("\tapp := %s()\n", getFuncName(&entry, false))
+				writer.WriteString(funcName)
+			}
+			if components {
+				// the components are stopped in the reverse order on return or on the termination signal
+				writer.WriteString(fmt.Sprintf("\tsignals := make(chan %s.Signal, 1)\n", osAlias))
+				writer.WriteString(fmt.Sprintf("\t%s.Notify(signals, %s.Interrupt, %s.SIGTERM)\n", signalAlias, osAlias, syscallAlias))
+				writer.WriteString("\tgo func() {\n")
+				writer.WriteString("\t\t<-signals\n")
+				writer.WriteString("\t\tstopComponents()\n")
+				writer.WriteString(fmt.Sprintf("\t\t%s.Exit(1)\n", osAlias))
+				writer.WriteString("\t}()\n")
+				writer.WriteString(fmt.Sprintf("\tif err := startComponents(%s.Background()); err != nil {\n", ctxAlias))
+				writer.WriteString("\t\tstopComponents()\n")
+				writer.WriteString(fmt.Sprintf("\t\t%s.Fprintf(%s.Stderr, \"cannot start the application: %%s\\n\", err)\n", fmtAlias, osAlias))
+				writer.WriteString(fmt.Sprintf("\t\t%s.Exit(1)\n", osAlias))
+				writer.WriteString("\t}\n")
+				writer.WriteString("\tdefer stopComponents()\n")
+			}
 			writer.WriteString("\tapp.Execute()\n")
 		case itemKind.String:
-			writer.WriteString(fmt.Sprintf("\tfmt.Println(%s)\n", entry.original))
+			writer.WriteString(fmt.Sprintf("\t%s.Println(%s)\n", fmtAlias, entry.original))
+		case itemKind.Value:
+			writer.WriteString(fmt.Sprintf("\t%s.Println(%s.%s)\n", fmtAlias, valueAlias, entry.name))
 		}
 	}
 	writer.WriteString("}\n\n")
+	if components {
+		g.writeComponents(&writer, ctxAlias, fmtAlias, osAlias, syncAlias)
+	}
 	// write items
 	if len(code) > 0 {
 		for _, v := range code {
 			writer.WriteString(v)
 		}
 	}
-	return nil
+	// the unformatted source is saved if it is incorrect to report the errors by the build
+	source, err := format.Source(writer.Bytes())
+	if err != nil {
+		source = writer.Bytes()
+	}
+	// save dependencies to a file
+	pd, _ := os.Getwd()
+	root := filepath.Join(pd, application)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		os.Mkdir(root, os.ModePerm)
+	}
+	return os.WriteFile(filepath.Join(root, depsFileName), source, 0644)
+}
+
+// writeComponents writes the functions which start the components in the order of creation,
+// the dependencies are created first, and stop them in the reverse order,
+// the components which are created by providers after the start are started at once
+func (g *Coder) writeComponents(writer *bytes.Buffer, ctxAlias, fmtAlias, osAlias, syncAlias alias) {
+	writer.WriteString("type component struct {\n")
+	writer.WriteString("\tname    string\n")
+	writer.WriteString(fmt.Sprintf("\tstart   func(%s.Context) error\n", ctxAlias))
+	writer.WriteString(fmt.Sprintf("\tstop    func(%s.Context) error\n", ctxAlias))
+	writer.WriteString("\tclose   func() error\n")
+	writer.WriteString("\tstarted bool\n")
+	writer.WriteString("}\n\n")
+	writer.WriteString("var components []component\n")
+	writer.WriteString(fmt.Sprintf("var componentsCtx %s.Context\n", ctxAlias))
+	writer.WriteString(fmt.Sprintf("var componentsMutex %s.Mutex\n", syncAlias))
+	writer.WriteString(fmt.Sprintf("var componentsOnce %s.Once\n\n", syncAlias))
+	writer.WriteString("func addComponent(c component) {\n")
+	writer.WriteString("\tcomponentsMutex.Lock()\n")
+	writer.WriteString("\tcomponents = append(components, c)\n")
+	writer.WriteString("\ti := len(components) - 1\n")
+	writer.WriteString("\tctx := componentsCtx\n")
+	writer.WriteString("\tcomponentsMutex.Unlock()\n")
+	writer.WriteString("\t// the component created by a provider after the application is started is started at once\n")
+	writer.WriteString("\tif ctx == nil || c.start == nil {\n")
+	writer.WriteString("\t\treturn\n")
+	writer.WriteString("\t}\n")
+	writer.WriteString("\tif err := c.start(ctx); err != nil {\n")
+	writer.WriteString("\t\tstopComponents()\n")
+	writer.WriteString(fmt.Sprintf("\t\t%s.Fprintf(%s.Stderr, \"cannot start the component: %%s: %%s\\n\", c.name, err)\n", fmtAlias, osAlias))
+	writer.WriteString(fmt.Sprintf("\t\t%s.Exit(1)\n", osAlias))
+	writer.WriteString("\t}\n")
+	writer.WriteString("\tcomponentsMutex.Lock()\n")
+	writer.WriteString("\tcomponents[i].started = true\n")
+	writer.WriteString("\tcomponentsMutex.Unlock()\n")
+	writer.WriteString("}\n\n")
+	writer.WriteString(fmt.Sprintf("func startComponents(ctx %s.Context) error {\n", ctxAlias))
+	writer.WriteString("\t// the lock is not held while the component starts because it can create other components\n")
+	writer.WriteString("\tfor i := 0; ; i++ {\n")
+	writer.WriteString("\t\tcomponentsMutex.Lock()\n")
+	writer.WriteString("\t\tif i >= len(components) {\n")
+	writer.WriteString("\t\t\t// the components which are created later are started by addComponent\n")
+	writer.WriteString("\t\t\tcomponentsCtx = ctx\n")
+	writer.WriteString("\t\t\tcomponentsMutex.Unlock()\n")
+	writer.WriteString("\t\t\treturn nil\n")
+	writer.WriteString("\t\t}\n")
+	writer.WriteString("\t\tc := components[i]\n")
+	writer.WriteString("\t\tcomponentsMutex.Unlock()\n")
+	writer.WriteString("\t\tif c.start != nil {\n")
+	writer.WriteString("\t\t\tif err := c.start(ctx); err != nil {\n")
+	writer.WriteString(fmt.Sprintf("\t\t\t\treturn %s.Errorf(\"%%s: %%w\", c.name, err)\n", fmtAlias))
+	writer.WriteString("\t\t\t}\n")
+	writer.WriteString("\t\t}\n")
+	writer.WriteString("\t\tcomponentsMutex.Lock()\n")
+	writer.WriteString("\t\tcomponents[i].started = true\n")
+	writer.WriteString("\t\tcomponentsMutex.Unlock()\n")
+	writer.WriteString("\t}\n")
+	writer.WriteString("}\n\n")
+	writer.WriteString("func stopComponents() {\n")
+	writer.WriteString("\tcomponentsOnce.Do(func() {\n")
+	writer.WriteString("\t\tcomponentsMutex.Lock()\n")
+	writer.WriteString("\t\tlist := append([]component{}, components...)\n")
+	writer.WriteString("\t\tcomponentsMutex.Unlock()\n")
+	writer.WriteString(fmt.Sprintf("\t\tctx := %s.Background()\n", ctxAlias))
+	writer.WriteString("\t\tfor i := len(list) - 1; i >= 0; i-- {\n")
+	writer.WriteString("\t\t\tc := list[i]\n")
+	writer.WriteString("\t\t\t// the component is not stopped if it is not started\n")
+	writer.WriteString("\t\t\tif c.stop != nil && (c.started || c.start == nil) {\n")
+	writer.WriteString("\t\t\t\tif err := c.stop(ctx); err != nil {\n")
+	writer.WriteString(fmt.Sprintf("\t\t\t\t\t%s.Fprintf(%s.Stderr, \"cannot stop the component: %%s: %%s\\n\", c.name, err)\n", fmtAlias, osAlias))
+	writer.WriteString("\t\t\t\t}\n")
+	writer.WriteString("\t\t\t}\n")
+	writer.WriteString("\t\t\tif c.close != nil {\n")
+	writer.WriteString("\t\t\t\tif err := c.close(); err != nil {\n")
+	writer.WriteString(fmt.Sprintf("\t\t\t\t\t%s.Fprintf(%s.Stderr, \"cannot close the component: %%s: %%s\\n\", c.name, err)\n", fmtAlias, osAlias))
+	writer.WriteString("\t\t\t\t}\n")
+	writer.WriteString("\t\t\t}\n")
+	writer.WriteString("\t\t}\n")
+	writer.WriteString("\t})\n")
+	writer.WriteString("}\n\n")
 }
 
-func (g *Coder) generateItems(entryPoint string, list items, types []typeInfo) ([]string, imports, error) {
+// hasComponents returns true if any struct item or the result of any function is started or stopped with the application
+func (g *Coder) hasComponents(list items, types []typeInfo) bool {
+	for _, it := range list {
+		switch it.kind {
+		case itemKind.Struct, itemKind.Inline:
+			if getLifecycle(types, getTypeId(&it)) != nil {
+				return true
+			}
+		case itemKind.Func:
+			if getResultLifecycle(types, getFuncInfo(types, &it)) != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *Coder) generateItems(entryPoint string, list items, types []typeInfo, lifecycle bool) ([]string, imports, error) {
 	code := []string{}
 	code2 := []string{}
 	imports := imports{}
 	adapter := adapter{}
 	adapter.imports = imports
-	// get all type of struct items to process
-	its := map[string]bool{}
-	g.getStructItems(entryPoint, list, its)
+	adapter.lifecycle = lifecycle
+	g.qualifyTypeArgs(list)
+	// get all type of struct items to process in the order of the dependency graph
+	its := g.getStructItems(entryPoint, list, map[string]bool{})
+	g.markFallibleItems(list, types)
 	// generate code for all type of struct items
 	var err error
 	gen := generator{}
 	gen.structGenerator = &structBegGen{
 		next: &structCreateGen{
 			next: &structInitGen{
-				next: &structEndGen{},
+				lifecycle: lifecycle,
+				next: &structEndGen{
+					lifecycle: lifecycle,
+				},
 			},
 		},
 	}
-	for i := range its {
+	done := map[string]bool{}
+	for _, i := range its {
 		if it, found := list[i]; found {
 			switch it.kind {
 			case itemKind.Func:
 				appendImport(imports, it.path+it.pkg)
-			case itemKind.Struct:
+			case itemKind.Struct, itemKind.Inline:
+				if it.singleton && !it.ref && len(it.path) > 0 {
+					// the value of the shared item is a copy of the single instance kept by the reference
+					code2, err = gen.shareValue(it, imports)
+					if err != nil {
+						return nil, nil, err
+					}
+					code = append(code, code2...)
+					it = getRefItem(it)
+				}
+				// the reference can be used by the value and by the dependencies
+				if funcName := getFuncName(&it, it.ref); done[funcName] {
+					continue
+				} else {
+					done[funcName] = true
+				}
 				code2, err = gen.createStruct(it, types, imports, &adapter)
 				if err != nil {
 					return nil, nil, err
@@ -264,31 +688,167 @@ func (g *Coder) generateItems(entryPoint string, list items, types []typeInfo) (
 		}
 	}
 	// append adapters
-	for _, value := range adapter.code {
-		code = append(code, value...)
+	for _, name := range adapter.names {
+		code = append(code, adapter.code[name]...)
 	}
 	return code, imports, nil
 }
 
-func (g *Coder) getStructItems(original string, list items, result map[string]bool) {
-	if result[original] {
+// qualifyTypeArgs marks the import paths of type arguments which have the same package name,
+// the names of the "Use" functions contain the full import paths of them to be unique
+func (g *Coder) qualifyTypeArgs(list items) {
+	// the items are processed once including the dependencies
+	done := map[*item]bool{}
+	var walk func(it *item, visit func(it *item))
+	walk = func(it *item, visit func(it *item)) {
+		if done[it] {
+			return
+		}
+		done[it] = true
+		visit(it)
+		for _, n := range it.deps {
+			walk(n.item, visit)
+		}
+	}
+	// collect the import paths by the package names
+	paths := map[string]map[string]bool{}
+	for name := range list {
+		it := list[name]
+		walk(&it, func(it *item) {
+			for _, arg := range it.args {
+				getTypeExpr(arg, func(path string) string {
+					base := filepath.Base(path)
+					if paths[base] == nil {
+						paths[base] = map[string]bool{}
+					}
+					paths[base][path] = true
+					return "_"
+				})
+			}
+		})
+	}
+	qualified := map[string]bool{}
+	for _, list := range paths {
+		if len(list) > 1 {
+			for path := range list {
+				qualified[path] = true
+			}
+		}
+	}
+	if len(qualified) == 0 {
 		return
 	}
+	done = map[*item]bool{}
+	for name, it := range list {
+		walk(&it, func(it *item) {
+			it.qualified = qualified
+		})
+		list[name] = it
+	}
+}
+
+// markFallibleItems marks the struct items which depend on functions returning an error,
+// the "Use" functions of these items return the error too
+func (g *Coder) markFallibleItems(list items, types []typeInfo) {
+	fallible := map[string]bool{}
+	var canFail func(d *item, f *field) bool
+	canFail = func(d *item, f *field) bool {
+		switch d.kind {
+		case itemKind.Func:
+			// the reference to a function is not called
+			if f != nil && f.Kind == reflect.Func && !d.exec {
+				return false
+			}
+			sig := getFuncInfo(types, d)
+			if sig != nil && isFallibleFunc(sig) {
+				return true
+			}
+			for i, n := range d.deps {
+				var p *field
+				if sig != nil && len(sig.In) > 0 {
+					x := sig.In[min(i, len(sig.In)-1)]
+					if sig.Variadic && i >= len(sig.In)-1 && x.Elem != nil {
+						x = *x.Elem
+					}
+					p = &x
+				}
+				if canFail(n.item, p) {
+					return true
+				}
+			}
+		case itemKind.Struct, itemKind.Inline:
+			// the provider returns the error itself
+			return (f == nil || f.Kind != reflect.Func) && fallible[d.original]
+		case itemKind.Slice, itemKind.Map, itemKind.Collection:
+			var elem *field
+			if f != nil {
+				elem = getUnderlying(types, f).Elem
+			}
+			for _, n := range d.deps {
+				if canFail(n.item, elem) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	// the items are processed until all dependencies are marked
+	for changed := true; changed; {
+		changed = false
+		for name, it := range list {
+			if fallible[name] || (it.kind != itemKind.Struct && it.kind != itemKind.Inline) {
+				continue
+			}
+			for _, n := range it.deps {
+				var f *field
+				if n.name != "." {
+					f, _ = getFieldInfo(types, getTypeId(&it), n.name)
+				}
+				if canFail(n.item, f) {
+					fallible[name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	// mark the items and the dependencies which refer to them
+	done := map[*item]bool{}
+	var mark func(it *item)
+	mark = func(it *item) {
+		if done[it] {
+			return
+		}
+		done[it] = true
+		it.fallible = fallible[it.original]
+		for _, n := range it.deps {
+			mark(n.item)
+		}
+	}
+	for name, it := range list {
+		mark(&it)
+		list[name] = it
+	}
+}
+
+// getStructItems returns the struct items starting from the original item,
+// the dependencies follow the item in the order of declaration
+func (g *Coder) getStructItems(original string, list items, done map[string]bool) []string {
+	if done[original] {
+		return nil
+	}
+	res := []string{}
 	if it, found := list[original]; found {
-		if it.kind == itemKind.Struct {
-			result[original] = true
+		if it.kind == itemKind.Struct || it.kind == itemKind.Inline {
+			done[original] = true
+			res = append(res, original)
 		}
 		for _, v := range it.deps {
 			switch v.item.kind {
-			case itemKind.Func:
-				for _, d := range v.item.deps {
-					if d.item.kind == itemKind.Struct {
-						g.getStructItems(d.item.original, list, result)
-					}
-				}
-			case itemKind.Struct:
-				g.getStructItems(v.item.original, list, result)
+			case itemKind.Func, itemKind.Struct, itemKind.Inline, itemKind.Slice, itemKind.Map, itemKind.Collection:
+				res = append(res, g.getStructItems(v.item.original, list, done)...)
 			}
 		}
 	}
+	return res
 }
@@ -1,9 +1,17 @@
 package sgo
 
 import (
+	"encoding/json"
 	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
+	"golang.org/x/tools/go/packages"
 	"gopkg.in/check.v1"
 )
 
@@ -56,6 +64,48 @@ func (s *sgoSuite) TestCodeParameters(c *check.C) {
 	}), check.Equals, true)
 }
 
+func (s *sgoSuite) TestCodeNestedParameters(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Field2", "github.com/nanomarkup/sgo/test.NewField2(\"Hello, (World)\")"},
+		{"Field3", "github.com/nanomarkup/sgo/test.NewField3(github.com/nanomarkup/sgo/test.NewField1V2(\"a, \\\"b\\\"\", \"c)\"))"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeParametersErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	input := "github.com/nanomarkup/sgo/test.NewField2(\"Hello\""
+	items[itemPath] = [][]string{
+		{"Field2", input},
+	}
+	s.coder.Init(items)
+	msg := fmt.Sprintf(SyntaxErrorF, FuncEndTokenIsMissing, len(input)+1, input)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(msg))
+	input = "github.com/nanomarkup/sgo/test.NewField1V2(\"a\",, \"b\")"
+	items[itemPath] = [][]string{
+		{"Field1", input},
+	}
+	s.coder.Init(items)
+	msg = fmt.Sprintf(SyntaxErrorF, fmt.Sprintf(FuncParamIsMissingF, 1), strings.Index(input, ",,")+2, input)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(msg))
+	input = "github.com/nanomarkup/sgo/test.NewField2(\"Hello\" \"World\")"
+	items[itemPath] = [][]string{
+		{"Field2", input},
+	}
+	s.coder.Init(items)
+	msg = fmt.Sprintf(SyntaxErrorF, fmt.Sprintf(TokenIsUnexpectedF, '"'), strings.Index(input, " \"W")+2, input)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(msg))
+}
+
 func (s *sgoSuite) TestCodeRefs(c *check.C) {
 	defer s.clean()
 	//f2Name := "github.com/nanomarkup/sgo/test.Field2"
@@ -153,24 +203,1157 @@ func (s *sgoSuite) TestCodeGroupItem(c *check.C) {
 	}), check.Equals, true)
 }
 
-// func (s *sgoSuite) TestCodeStructInitialization(c *check.C) {
-// 	defer s.clean()
-// 	items := s.copyItems()
-// 	items[itemPath] = map[string]string{
-// 		"Field2": "github.com/nanomarkup/sgo/test.Field2",
-// 		"Field2V2": "github.com/nanomarkup/sgo/test.Field2 {	Name \"World\" }",
-// 	}
-// 	items["github.com/nanomarkup/sgo/test.Field2"] = map[string]string{
-// 		"Name": "\"Hello\"",
-// 	}
-// 	s.coder.Init(items)
-// 	c.Assert(s.coder.Generate(s.name), check.IsNil)
-// 	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
-// 		if err := s.builder.Build(s.name); err != nil {
-// 			t.Error(err)
-// 		}
-// 	}), check.Equals, true)
-// }
+func (s *sgoSuite) TestCodeStructInitialization(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Field2", "github.com/nanomarkup/sgo/test.Field2"},
+		{"Field2V2", "github.com/nanomarkup/sgo/test.Field2 {	Name \"World\" }"},
+		{"Field4", "*github.com/nanomarkup/sgo/test.Field4 { Name \"Hello { World }\" Port 8080 Field github.com/nanomarkup/sgo/test.Field2 { Name \"Nested\" } Runner *github.com/nanomarkup/sgo/test.RunnerImpl }"},
+	}
+	items["github.com/nanomarkup/sgo/test.Field2"] = [][]string{
+		{"Name", "\"Hello\""},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeStructInitializationErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Field2", "github.com/nanomarkup/sgo/test.Field2 { Name }"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, fmt.Sprintf(InlineFieldValueIsMissingF, "Name")+".*")
+	items[itemPath] = [][]string{
+		{"Field2", "github.com/nanomarkup/sgo/test.Field2 { Name \"Hello }"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, QuoteEndTokenIsMissing+".*")
+}
+
+func (s *sgoSuite) TestCodeSlices(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Names", "[ \"Hello, World\", \"]\" ]"},
+		{"Ports", "[80, 443]"},
+		{"Fields", "[*github.com/nanomarkup/sgo/test.Field2, *github.com/nanomarkup/sgo/test.Field2 { Name \"Hi\" }]"},
+		{"Runners", "[*github.com/nanomarkup/sgo/test.RunnerImpl]"},
+		{"Matrix", "[[1, 2.5], []]"},
+	}
+	items["github.com/nanomarkup/sgo/test.Field2"] = [][]string{
+		{"Name", "\"Hello\""},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeSlicesErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Ports", "[80, 443, 8080, 8081]"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, fmt.Sprintf(SliceIsOutOfRangeF, "\\[80, 443, 8080, 8081\\]"))
+	items[itemPath] = [][]string{
+		{"Ports", "[80, , 443]"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, fmt.Sprintf(SliceElementIsMissingF, 1)+".*")
+}
+
+func (s *sgoSuite) TestCodeMaps(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Labels", "{ \"app\": \"sgo\", \"a: b, c\": \"}\" }"},
+		{"Weights", "{1: 0.5, 2: 1}"},
+		{"Handlers", "{\"run\": *github.com/nanomarkup/sgo/test.RunnerImpl}"},
+		{"Settings", "{\"debug\": true, \"name\": \"sgo\"}"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeMapsErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Labels", "{1: \"sgo\"}"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, fmt.Sprintf(ValueIsIncompatibleF, "1", "string"))
+	items[itemPath] = [][]string{
+		{"Weights", "{1.5: 1}"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, fmt.Sprintf(ValueIsIncompatibleF, "1.5", "int"))
+	items[itemPath] = [][]string{
+		{"Labels", "{\"app\" \"sgo\"}"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, fmt.Sprintf(MapSeparatorIsMissingF, "\"app\"")+".*")
+	items[itemPath] = [][]string{
+		{"Labels", "{\"app\": \"sgo\", \"app\": \"sb\"}"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, fmt.Sprintf(MapKeyIsDuplicatedF, "\"app\"")+".*")
+}
+
+func (s *sgoSuite) TestCodeEnvs(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"DSN", "env(\"SGO_DSN\", \"postgres://localhost:5432\")"},
+		{"Int1", "env(\"SGO_INT\", 5)"},
+		{"Port", "env(\"SGO_PORT\", 8080)"},
+		{"Debug", "env(\"SGO_DEBUG\", false)"},
+		{"Timeout", "env(\"SGO_TIMEOUT\", \"5s\")"},
+		{"Float1", "env(\"SGO_FLOAT\", 0.5)"},
+		{"Ratio", "env(\"SGO_RATIO\")"},
+		{"Value", "env(\"SGO_VALUE\")"},
+		{"Field2", "github.com/nanomarkup/sgo/test.Field2 { Name env(\"SGO_NAME\", \"sgo\") }"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeEnvsErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"DSN", "env()"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(EnvParamsAreIncorrectF, "env()")))
+	items[itemPath] = [][]string{
+		{"DSN", "env(SGO_DSN)"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(EnvParamsAreIncorrectF, "env(SGO_DSN)")))
+	items[itemPath] = [][]string{
+		{"Field1", "env(\"SGO_FIELD\")"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, fmt.Sprintf(TypeDoesNotSupportedF, "struct"))
+}
+
+func (s *sgoSuite) TestCodeNamedTypes(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Timeout", "\"1m30s\""},
+		{"Level", "3"},
+		{"MinLevel", "github.com/hashicorp/go-hclog.LevelFromString(\"info\")"},
+		{"HttpPort", "8080"},
+		{"Mode", "\"debug\""},
+		{"Modes", "[ \"debug\", \"release\" ]"},
+		{"Aliases", "[ \"a\", \"b\" ]"},
+		{"Levels", "{ \"debug\": 1, \"release\": 4 }"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeNamedTypesErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Timeout", "\"5 parsecs\""},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(DurationIsIncorrectF, "\"5 parsecs\"")))
+	items[itemPath] = [][]string{
+		{"Mode", "5"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ValueIsIncompatibleF, "5", "string")))
+}
+
+func (s *sgoSuite) TestCodeValues(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Output", "=os.Stdout"},
+		{"File", "=os.Stderr"},
+		{"Args", "=os.Args"},
+		{"DSN", "=time.RFC3339"},
+		{"Timeout", "=time.Second"},
+		{"Port", "=math.MaxUint16"},
+		{"Small", "=math.MaxInt8"},
+		{"Ratio", "=math.Pi"},
+		{"Level", "=github.com/hashicorp/go-hclog.Info"},
+		{"Value", "=os.Stdin"},
+		{"Field2", "github.com/nanomarkup/sgo/test.NewField2(=time.Kitchen)"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeValuesErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Output", "=time.RFC3339"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ValueIsIncompatibleF, "=time.RFC3339", "interface")))
+	items[itemPath] = [][]string{
+		{"Debug", "=os.Stdout"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ValueIsIncompatibleF, "=os.Stdout", "bool")))
+	// the constants are converted if the value is representable by the field type only
+	items[itemPath] = [][]string{
+		{"Int1", "=math.Pi"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ValueIsIncompatibleF, "=math.Pi", "int")))
+	items[itemPath] = [][]string{
+		{"Small", "=math.MaxInt64"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ValueIsIncompatibleF, "=math.MaxInt64", "int16")))
+	// the variables are not converted
+	items[itemPath] = [][]string{
+		{"Small", "=github.com/nanomarkup/sgo/test.Total"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ValueIsIncompatibleF, "=github.com/nanomarkup/sgo/test.Total", "int16")))
+}
+
+func (s *sgoSuite) TestCodeGenerics(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	cacheName := "github.com/nanomarkup/sgo/test.Cache[string, *github.com/nanomarkup/sgo/test.Field2]"
+	pairName := "*github.com/nanomarkup/sgo/test.Pair[int, github.com/nanomarkup/sgo/test.Cache[string, []string]]"
+	items[itemPath] = [][]string{
+		{"Cache", cacheName},
+		{"Cache2", "github.com/nanomarkup/sgo/test.NewCache[int, github.com/nanomarkup/sgo/test.Field2](5)"},
+		{"Pair", pairName},
+	}
+	items[cacheName] = [][]string{
+		{"Size", "10"},
+		{"Items", "{ \"a\": *github.com/nanomarkup/sgo/test.Field2 }"},
+	}
+	items[pairName[1:]] = [][]string{
+		{"First", "1"},
+		{"Second", "github.com/nanomarkup/sgo/test.NewCache[string, []string](2)"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+	// the type arguments of the packages with the same name are named by the import paths
+	usersName := "github.com/nanomarkup/sgo/testdata/users.Users"
+	adminsName := "github.com/nanomarkup/sgo/test.Cache[string, *github.com/nanomarkup/sgo/testdata/a/models.User]"
+	visitorsName := "github.com/nanomarkup/sgo/test.Cache[string, *github.com/nanomarkup/sgo/testdata/b/models.User]"
+	items[appName] = [][]string{{"entry", usersName}}
+	items[usersName] = [][]string{
+		{"Admins", adminsName},
+		{"Visitors", visitorsName},
+	}
+	items[adminsName] = [][]string{
+		{"Size", "1"},
+	}
+	items[visitorsName] = [][]string{
+		{"Size", "2"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	data, err := os.ReadFile(filepath.Join(s.name, depsFileName))
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.Contains(string(data), "func UseTestCacheOfStringPtrGithubComNanomarkupSgoTestdataAModelsUser()"), check.Equals, true)
+	c.Assert(strings.Contains(string(data), "func UseTestCacheOfStringPtrGithubComNanomarkupSgoTestdataBModelsUser()"), check.Equals, true)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-BuildPaths", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+	out, err := exec.Command(filepath.Join(s.name, s.name)).CombinedOutput()
+	c.Assert(err, check.IsNil)
+	c.Assert(string(out), check.Equals, "1 2\n")
+}
+
+func (s *sgoSuite) TestCodeGenericsErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Cache", "github.com/nanomarkup/sgo/test.Cache[string, ]"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(TypeArgsAreIncorrectF, "github.com/nanomarkup/sgo/test.Cache[string, ]")))
+}
+
+func (s *sgoSuite) TestCodeInternalTypes(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	serverName := "net/http.Server"
+	items[itemPath] = [][]string{
+		{"Server", "*" + serverName},
+	}
+	items[serverName] = [][]string{
+		{"Addr", "\":8080\""},
+		{"ReadTimeout", "\"5s\""},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeInternalTypesErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	// the errors of declarations are reported
+	items[appName] = [][]string{{"entry", "github.com/nanomarkup/sgo/testdata/broken.Broken"}}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, ".*undefined: Undefined")
+}
+
+func (s *sgoSuite) TestCodeCompositeTypes(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Notifier", "*github.com/nanomarkup/sgo/test.NotifierImpl"},
+		{"Events", "=github.com/nanomarkup/sgo/test.Events"},
+		{"Updates", "=github.com/nanomarkup/sgo/test.Events"},
+		{"Format", "fmt.Sprintf()"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	data, err := os.ReadFile(filepath.Join(s.name, depsFileName))
+	c.Assert(err, check.IsNil)
+	// the adapter keeps the channel direction and the variadic parameter
+	c.Assert(strings.Contains(string(data), "Notify(a1 p1.Runner, a2 chan<- string, a3 ...string) (r1 []string)"), check.Equals, true)
+	c.Assert(strings.Contains(string(data), "(b1, a2, a3...)"), check.Equals, true)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeCompositeTypesErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	// the channel of the implementation has another direction
+	items[itemPath] = [][]string{
+		{"Notifier", "*github.com/nanomarkup/sgo/test.NotifierImpl2"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ParamsDoesNotSupportedF, "chan<- string", "chan string")))
+	// the receive-only channel cannot be sent to
+	items[itemPath] = [][]string{
+		{"Events", "=github.com/nanomarkup/sgo/test.Updates"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ValueIsIncompatibleF, "=github.com/nanomarkup/sgo/test.Updates", "chan")))
+}
+
+func (s *sgoSuite) TestCodeEmbeddedFields(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	serviceName := "github.com/nanomarkup/sgo/test.Service"
+	items[itemPath] = [][]string{
+		{"Service", "*" + serviceName},
+		{"Runner", "*" + serviceName},
+	}
+	items[serviceName] = [][]string{
+		{"Name", "\"api\""},
+		{"Timeout", "\"5s\""},
+		{"Host", "\"localhost\""},
+		{"Addr", "\":8080\""},
+		{"ReadTimeout", "\"10s\""},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	data, err := os.ReadFile(filepath.Join(s.name, depsFileName))
+	c.Assert(err, check.IsNil)
+	// the embedded pointer is created once before the promoted fields are assigned
+	c.Assert(strings.Count(string(data), "if v.Server == nil {"), check.Equals, 1)
+	c.Assert(regexp.MustCompile(`v\.Server = &p\d+\.Server\{\}`).MatchString(string(data)), check.Equals, true)
+	// the promoted method implements the interface without an adapter
+	c.Assert(strings.Contains(string(data), "v.Runner = UseTestServiceRef()"), check.Equals, true)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeEmbeddedFieldsErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	dualName := "github.com/nanomarkup/sgo/test.Dual"
+	items[itemPath] = [][]string{
+		{"Dual", dualName},
+	}
+	// the field is promoted by both embedded structs at the same depth
+	items[dualName] = [][]string{
+		{"Name", "\"dual\""},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(FieldIsAmbiguousF, "Name", dualName)))
+	// the field is promoted by the same struct embedded twice at the same depth
+	twinsName := "github.com/nanomarkup/sgo/test.Twins"
+	items[itemPath] = [][]string{
+		{"Twins", twinsName},
+	}
+	items[twinsName] = [][]string{
+		{"Host", "\"localhost\""},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(FieldIsAmbiguousF, "Host", twinsName)))
+	items[itemPath] = [][]string{
+		{"Dual", dualName},
+	}
+	// the fields promoted by a single embedded struct are assigned
+	items[dualName] = [][]string{
+		{"Port", "8080"},
+		{"Field", "github.com/nanomarkup/sgo/test.Field2"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+}
+
+func (s *sgoSuite) TestCodeFuncCalls(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Service", "github.com/nanomarkup/sgo/test.NewService(\"api\", 8080, *github.com/nanomarkup/sgo/test.RunnerImpl, *github.com/nanomarkup/sgo/test.RunnerImpl2)"},
+		{"Field3", "github.com/nanomarkup/sgo/test.NewField3(github.com/nanomarkup/sgo/test.NewField1())"},
+		{"Hello", "github.com/nanomarkup/sgo/test.Hello()"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	data, err := os.ReadFile(filepath.Join(s.name, depsFileName))
+	c.Assert(err, check.IsNil)
+	// the arguments are converted to the parameter types
+	c.Assert(regexp.MustCompile(`NewService\("api", p\d+\.Port\(8080\), UseTestRunnerImplRef\(\), UseTestRunnerImpl2Ref\(\)\)`).MatchString(string(data)), check.Equals, true)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeFuncCallsErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	// the number of arguments is incorrect
+	funcName := "github.com/nanomarkup/sgo/test.NewField1V2(\"Ariana\")"
+	items[itemPath] = [][]string{
+		{"Field1", funcName},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(FuncArgsAreIncorrectF, funcName, 1, "2")))
+	funcName = "github.com/nanomarkup/sgo/test.NewService()"
+	items[itemPath] = [][]string{
+		{"Service", funcName},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(FuncArgsAreIncorrectF, funcName, 0, "at least 2")))
+	// the literal argument has another kind
+	items[itemPath] = [][]string{
+		{"Field2", "github.com/nanomarkup/sgo/test.NewField2(5)"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Errorf(FuncArgIsIncorrectF, 1,
+		"github.com/nanomarkup/sgo/test.NewField2(5)", fmt.Errorf(ValueIsIncompatibleF, "5", "string")).Error()))
+	// the result has another type
+	funcName = "github.com/nanomarkup/sgo/test.NewField2(\"Ariana\")"
+	items[itemPath] = [][]string{
+		{"Field1", funcName},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(FuncResultIsIncompatibleF, funcName, "github.com/nanomarkup/sgo/test.Field1")))
+	// the referenced function has another signature
+	funcName = "github.com/nanomarkup/sgo/test.EmptyFunc()"
+	items[itemPath] = [][]string{
+		{"Hello", funcName},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(FuncIsIncompatibleF, funcName, "func(string)")))
+	// the function does not exist
+	items[itemPath] = [][]string{
+		{"Field1", "github.com/nanomarkup/sgo/test.NewMissing()"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(TypeIsMissingF, "github.com/nanomarkup/sgo/test.NewMissing")))
+}
+
+func (s *sgoSuite) TestCodeFallibleFuncs(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	repoName := "github.com/nanomarkup/sgo/test.Repository"
+	items[itemPath] = [][]string{
+		{"DB", "github.com/nanomarkup/sgo/test.NewDatabase(\"postgres://localhost\")"},
+		{"Repo", "*" + repoName},
+		{"Repos", "[*" + repoName + "]"},
+		{"RepoFunc", "*" + repoName},
+	}
+	items[repoName] = [][]string{
+		{"scope", "singleton"},
+		{"DB", "github.com/nanomarkup/sgo/test.NewDatabase(\"\")"},
+	}
+	items[appName] = [][]string{{"entry", itemPath}, {"lifecycle", "true"}}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	data, err := os.ReadFile(filepath.Join(s.name, depsFileName))
+	c.Assert(err, check.IsNil)
+	// the errors are propagated by the "Use" functions
+	c.Assert(regexp.MustCompile(`func UseTestRepositoryRef\(\) \(\*p\d+\.Repository, error\)`).MatchString(string(data)), check.Equals, true)
+	c.Assert(regexp.MustCompile(`func UseTestItem1\(\) \(p\d+\.Item1, error\)`).MatchString(string(data)), check.Equals, true)
+	c.Assert(strings.Contains(string(data), "return UseTestRepositoryRef()"), check.Equals, true)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+	// the application reports the failing component and exits with an error
+	out, err := exec.Command(filepath.Join(s.name, s.name)).CombinedOutput()
+	c.Assert(err, check.NotNil)
+	c.Assert(strings.Contains(string(out), "github.com/nanomarkup/sgo/test.NewDatabase(\"\"): the DSN is empty"), check.Equals, true)
+	// the components created before the failure are closed
+	c.Assert(strings.Contains(string(out), "close postgres://localhost"), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeFallibleFuncsErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	repoName := "github.com/nanomarkup/sgo/test.Repository"
+	// the provider without an error cannot create the fallible item
+	items[itemPath] = [][]string{
+		{"RepoLazy", "*" + repoName},
+	}
+	items[repoName] = [][]string{
+		{"DB", "github.com/nanomarkup/sgo/test.NewDatabase(\"\")"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ProviderErrorIsMissingF, "*"+repoName, "RepoLazy")))
+}
+
+func (s *sgoSuite) TestCodeComponents(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	workerName := "github.com/nanomarkup/sgo/test.Worker"
+	items[itemPath] = [][]string{
+		{"Worker", "*" + workerName},
+	}
+	items[workerName] = [][]string{
+		{"DB", "github.com/nanomarkup/sgo/test.NewDatabase(\"db\")"},
+		{"Name", "\"worker\""},
+	}
+	// the components are not started and stopped by default
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	data, err := os.ReadFile(filepath.Join(s.name, depsFileName))
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.Contains(string(data), "addComponent"), check.Equals, false)
+	items[appName] = [][]string{{"entry", itemPath}, {"lifecycle", "true"}}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	data, err = os.ReadFile(filepath.Join(s.name, depsFileName))
+	c.Assert(err, check.IsNil)
+	// the created components are registered by the "Use" functions
+	c.Assert(strings.Contains(string(data), "start: v.Start, stop: v.Stop})"), check.Equals, true)
+	c.Assert(regexp.MustCompile(`close: v\d+\.Close\}\)`).MatchString(string(data)), check.Equals, true)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+	// the components are started in the dependency order and stopped in the reverse order
+	out, err := exec.Command(filepath.Join(s.name, s.name)).CombinedOutput()
+	c.Assert(err, check.IsNil)
+	c.Assert(string(out), check.Equals, "start worker\nexecute\nstop worker\nclose db\n")
+	// the component created by the provider after the start is started at once
+	items[itemPath] = [][]string{
+		{"Workers", "*" + workerName},
+	}
+	items[workerName] = [][]string{
+		{"Name", "\"worker\""},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-BuildLazy", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+	out, err = exec.Command(filepath.Join(s.name, s.name)).CombinedOutput()
+	c.Assert(err, check.IsNil)
+	c.Assert(string(out), check.Equals, "start worker\nexecute\nstop worker\n")
+	// the adapter embeds the started component
+	items[itemPath] = [][]string{
+		{"Handler", "*github.com/nanomarkup/sgo/test.Processor"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-BuildAdapter", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+	out, err = exec.Command(filepath.Join(s.name, s.name)).CombinedOutput()
+	c.Assert(err, check.IsNil)
+	m := regexp.MustCompile(`^start (0x[0-9a-f]+)\nhandle (0x[0-9a-f]+)\nexecute\nstop (0x[0-9a-f]+)\n$`).FindStringSubmatch(string(out))
+	c.Assert(m, check.HasLen, 4, check.Commentf("%s", out))
+	c.Assert(m[2], check.Equals, m[1])
+	c.Assert(m[3], check.Equals, m[1])
+}
+
+func (s *sgoSuite) TestCodeComponentsErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	workerName := "github.com/nanomarkup/sgo/test.Worker"
+	// the methods with the pointer receiver are bound to the copied instance
+	items[itemPath] = [][]string{
+		{"Job", workerName},
+	}
+	items[workerName] = [][]string{
+		{"Name", "\"worker\""},
+	}
+	items[appName] = [][]string{{"entry", itemPath}, {"lifecycle", "true"}}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ComponentIsCopiedF, workerName)))
+	funcName := "github.com/nanomarkup/sgo/test.NewJob(\"job\")"
+	items[itemPath] = [][]string{
+		{"Job", funcName},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ComponentIsCopiedF, funcName)))
+}
+
+func (s *sgoSuite) TestCodeDeterministic(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	workerName := "github.com/nanomarkup/sgo/test.Worker"
+	items[itemPath] = [][]string{
+		{"DSN", "env(\"SGO_DSN\", \"postgres://localhost:5432\")"},
+		{"Port", "env(\"SGO_PORT\", 8080)"},
+		{"Timeout", "env(\"SGO_TIMEOUT\", \"5s\")"},
+		{"Field2", "github.com/nanomarkup/sgo/test.NewField2(\"Vitalii\")"},
+		{"Field2Ref", "*github.com/nanomarkup/sgo/test.Field2"},
+		{"Runners", "[*github.com/nanomarkup/sgo/test.RunnerImpl, *github.com/nanomarkup/sgo/test.RunnerImpl2]"},
+		{"Greeter", "*github.com/nanomarkup/sgo/test.GreeterImpl"},
+		{"Notifier", "*github.com/nanomarkup/sgo/test.NotifierImpl"},
+		{"Server", "*net/http.Server"},
+		{"Worker", "*" + workerName},
+	}
+	items[workerName] = [][]string{
+		{"DB", "github.com/nanomarkup/sgo/test.NewDatabase(\"db\")"},
+		{"Name", "\"worker\""},
+	}
+	items[appName] = [][]string{{"entry", itemPath}, {"lifecycle", "true"}}
+	s.coder.Init(items)
+	// the same items produce the same source every time
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	first, err := os.ReadFile(filepath.Join(s.name, depsFileName))
+	c.Assert(err, check.IsNil)
+	for i := 0; i < 5; i++ {
+		c.Assert(s.coder.Generate(s.name), check.IsNil)
+		data, err := os.ReadFile(filepath.Join(s.name, depsFileName))
+		c.Assert(err, check.IsNil)
+		c.Assert(string(data), check.Equals, string(first))
+	}
+	// the source is formatted
+	formatted, err := format.Source(first)
+	c.Assert(err, check.IsNil)
+	c.Assert(string(first), check.Equals, string(formatted))
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeTypeCache(c *check.C) {
+	defer s.clean()
+	// the cache is kept in a temporary folder
+	root := c.MkDir()
+	s.coder.cacheDir = root
+	defer func() { s.coder.cacheDir = "" }()
+	// the packages are loaded by the first generation only
+	loads := 0
+	load := loadPackages
+	defer func() { loadPackages = load }()
+	loadPackages = func(cfg *packages.Config, patterns ...string) ([]*packages.Package, error) {
+		loads++
+		return load(cfg, patterns...)
+	}
+	s.coder.Init(s.copyItems())
+	// the type details are cached by the first generation and reused by the next one
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(loads > 0, check.Equals, true)
+	wd, err := os.Getwd()
+	c.Assert(err, check.IsNil)
+	dir, _, err := getCacheDir(root, wd)
+	c.Assert(err, check.IsNil)
+	files, err := os.ReadDir(dir)
+	c.Assert(err, check.IsNil)
+	c.Assert(len(files) > 0, check.Equals, true)
+	loads = 0
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(loads, check.Equals, 0)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+	// the cache is removed together with the generated files
+	c.Assert(s.coder.Clean(s.name), check.IsNil)
+	_, err = os.Stat(dir)
+	c.Assert(os.IsNotExist(err), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeCyclesErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	f2Name := "github.com/nanomarkup/sgo/test.Field2"
+	items[itemPath] = [][]string{
+		{"Field2", f2Name},
+	}
+	items[f2Name] = [][]string{
+		{"Name", itemPath},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(DependencyCycleIsDetectedF,
+		itemPath+".Field2 → "+f2Name+".Name → "+itemPath)))
+	// the cycle through the parameter of the function
+	f3Name := "github.com/nanomarkup/sgo/test.NewField3(*github.com/nanomarkup/sgo/test.Item1)"
+	items = s.copyItems()
+	items[itemPath] = [][]string{
+		{"Field3", f3Name},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(DependencyCycleIsDetectedF,
+		itemPath+".Field3 → "+f3Name+" → "+itemPath)))
+}
+
+func (s *sgoSuite) TestCodeSingletons(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	f2Name := "github.com/nanomarkup/sgo/test.Field2"
+	f2RefName := "*github.com/nanomarkup/sgo/test.Field2"
+	greeterName := "github.com/nanomarkup/sgo/test.GreeterImpl"
+	greeterRefName := "*github.com/nanomarkup/sgo/test.GreeterImpl"
+	items[itemPath] = [][]string{
+		{"Field2", f2Name},
+		{"Field2Ref", f2RefName},
+		{"Fields", fmt.Sprintf("[ %s, %s ]", f2RefName, f2RefName)},
+		{"Greeter", greeterRefName},
+		{"Greeters", fmt.Sprintf("[ %s, %s ]", greeterRefName, greeterRefName)},
+	}
+	items[f2Name] = [][]string{
+		{"scope", "singleton"},
+		{"Name", "\"Hello\""},
+	}
+	items[greeterName] = [][]string{
+		{"scope", "singleton"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	data, err := os.ReadFile(filepath.Join(s.name, depsFileName))
+	c.Assert(err, check.IsNil)
+	// the value is a copy of the single instance kept by the reference
+	c.Assert(strings.Contains(string(data), "return *UseTestField2Ref()"), check.Equals, true)
+	c.Assert(strings.Contains(string(data), "useTestField2Once"), check.Equals, false)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+	// all consumers get the same instance
+	out, err := exec.Command(filepath.Join(s.name, s.name)).CombinedOutput()
+	c.Assert(err, check.IsNil)
+	c.Assert(string(out), check.Equals, "shared true\nexecute\n")
+	// the adapter of the value embeds the single instance too
+	items[itemPath] = [][]string{
+		{"Greeter", greeterName},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	data, err = os.ReadFile(filepath.Join(s.name, depsFileName))
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.Contains(string(data), "v.GreeterImpl = UseTestGreeterImplRef()"), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeSingletonsErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	f2Name := "github.com/nanomarkup/sgo/test.Field2"
+	items[itemPath] = [][]string{
+		{"Field2", f2Name},
+	}
+	items[f2Name] = [][]string{
+		{"scope", "request"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ScopeIsIncorrectF, "request", f2Name)))
+}
+
+func (s *sgoSuite) TestCodeProviders(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	f2Name := "github.com/nanomarkup/sgo/test.Field2"
+	items[itemPath] = [][]string{
+		{"Lazy", f2Name},
+		{"Provider", "*" + f2Name},
+		{"Factory", "*github.com/nanomarkup/sgo/test.RunnerImpl"},
+		{"Greeters2", "[ *github.com/nanomarkup/sgo/test.GreeterImpl ]"},
+	}
+	items[f2Name] = [][]string{
+		{"Name", "\"Hello\""},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeProvidersErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	f2Name := "github.com/nanomarkup/sgo/test.Field2"
+	items[itemPath] = [][]string{
+		{"Hello", f2Name},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ProviderIsIncorrectF, "Hello", f2Name)))
+}
+
+func (s *sgoSuite) TestCodeProfiles(c *check.C) {
+	defer s.clean()
+	f2Name := "github.com/nanomarkup/sgo/test.Field2"
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Field2", f2Name},
+		{"Field2Ref", "*" + f2Name},
+	}
+	items[f2Name] = [][]string{
+		{"Name", "\"dev\""},
+	}
+	items["[prod]"+f2Name] = [][]string{
+		{"Name", "\"prod\""},
+	}
+	items["[test]"+f2Name] = [][]string{
+		{"Name", "\"test\""},
+	}
+	generate := func(profile string) string {
+		s.coder.Init(items)
+		c.Assert(s.coder.Generate(s.name), check.IsNil)
+		data, err := os.ReadFile(filepath.Join(s.name, depsFileName))
+		c.Assert(err, check.IsNil)
+		c.Assert(strings.Contains(string(data), fmt.Sprintf("v.Name = \"%s\"", profile)), check.Equals, true)
+		c.Assert(s.t.Run(fmt.Sprintf("%s-%s-Build", getTestName(c), profile), func(t *testing.T) {
+			if err := s.builder.Build(s.name); err != nil {
+				t.Error(err)
+			}
+		}), check.Equals, true)
+		return string(data)
+	}
+	// the ungrouped items are used without a profile
+	c.Assert(strings.Contains(generate("dev"), "\"prod\""), check.Equals, false)
+	// the application attribute selects a profile
+	items[appName] = [][]string{{"entry", itemPath}, {"profile", "test"}}
+	generate("test")
+	// the environment variable overrides the application attribute
+	os.Setenv(profileEnvName, "prod")
+	defer os.Unsetenv(profileEnvName)
+	generate("prod")
+	// the coder option overrides the environment variable
+	s.coder.Profile = "test"
+	defer func() {
+		s.coder.Profile = ""
+	}()
+	generate("test")
+}
+
+func (s *sgoSuite) TestCodeInheritance(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	f4Name := "github.com/nanomarkup/sgo/test.Field4"
+	items[itemPath] = [][]string{
+		{"Field4", "[Hey]*" + f4Name},
+	}
+	items[f4Name] = [][]string{
+		{"Name", "\"Hello\""},
+		{"Port", "1"},
+		{"Runner", "*github.com/nanomarkup/sgo/test.RunnerImpl"},
+	}
+	items["[Hi]"+f4Name] = [][]string{
+		{"extends"},
+		{"Port", "2"},
+	}
+	items["[Hey]"+f4Name] = [][]string{
+		{"extends", "[Hi]" + f4Name},
+		{"Name", "\"Hey\""},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	data, err := os.ReadFile(filepath.Join(s.name, depsFileName))
+	c.Assert(err, check.IsNil)
+	c.Assert(string(data), check.Matches, "(?s).*v.Name = \"Hey\"\n\tv.Port = 2\n\tv.Runner = .*")
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeInheritanceErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	f2Name := "github.com/nanomarkup/sgo/test.Field2"
+	items[itemPath] = [][]string{
+		{"Field2", "[Hi]" + f2Name},
+	}
+	items["[Hi]"+f2Name] = [][]string{
+		{"extends"},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(BaseItemIsMissingF, f2Name, "[Hi]"+f2Name)))
+	items[f2Name] = [][]string{
+		{"extends", "[Hi]" + f2Name},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(DependencyCycleIsDetectedF,
+		"[Hi]"+f2Name+" → "+f2Name+" → [Hi]"+f2Name)))
+}
+
+func (s *sgoSuite) TestCodeGraph(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	f2Name := "github.com/nanomarkup/sgo/test.Field2"
+	greeterName := "*github.com/nanomarkup/sgo/test.GreeterImpl"
+	items[itemPath] = [][]string{
+		{"Int1", "5"},
+		{"Field2V2", "[Hi]" + f2Name},
+		{"Greeter", greeterName},
+	}
+	items["[Hi]"+f2Name] = [][]string{
+		{"scope", "singleton"},
+		{"Name", "\"Hi\""},
+	}
+	s.coder.Init(items)
+	// json
+	data, err := s.coder.Graph(s.name, GraphFormatJson)
+	c.Assert(err, check.IsNil)
+	gr := graph{}
+	c.Assert(json.Unmarshal([]byte(data), &gr), check.IsNil)
+	c.Assert(gr.Entry, check.Equals, itemPath)
+	c.Assert(gr.Nodes, check.DeepEquals, []graphNode{
+		{Id: "\"Hi\"", Kind: "string", Value: "\"Hi\""},
+		{Id: "*github.com/nanomarkup/sgo/test.GreeterImpl", Kind: "struct", Type: "github.com/nanomarkup/sgo/test.GreeterImpl", Ref: true},
+		{Id: "5", Kind: "number", Value: "5"},
+		{Id: "[Hi]" + f2Name, Kind: "struct", Type: f2Name, Group: "Hi", Singleton: true},
+		{Id: itemPath, Kind: "struct", Type: itemPath},
+	})
+	c.Assert(gr.Edges, check.DeepEquals, []graphEdge{
+		{From: itemPath, To: "5", Field: "Int1"},
+		{From: itemPath, To: "[Hi]" + f2Name, Field: "Field2V2"},
+		{From: "[Hi]" + f2Name, To: "\"Hi\"", Field: "Name"},
+		{From: itemPath, To: greeterName, Field: "Greeter", Adapter: "UseTestGreeterImplTestGreeterAdapterRef"},
+	})
+	// dot
+	data, err = s.coder.Graph(s.name, GraphFormatDot)
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.HasPrefix(data, "digraph \".test\" {\n"), check.Equals, true)
+	c.Assert(strings.Contains(data, fmt.Sprintf("\t\"%s\" -> \"%s\" [label=\"Greeter (UseTestGreeterImplTestGreeterAdapterRef)\", style=dashed];\n", itemPath, greeterName)), check.Equals, true)
+	// mermaid
+	data, err = s.coder.Graph(s.name, GraphFormatMermaid)
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.HasPrefix(data, "graph TD\n\tn1(\"#quot;Hi#quot;\")\n"), check.Equals, true)
+	c.Assert(strings.Contains(data, "\tn5 -->|\"Int1\"| n3\n"), check.Equals, true)
+	// the unknown format
+	_, err = s.coder.Graph(s.name, "svg")
+	c.Assert(err, check.ErrorMatches, fmt.Sprintf(GraphFormatIsUnknownF, "svg"))
+}
+
+func (s *sgoSuite) TestCodeAutowire(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	f4Name := "github.com/nanomarkup/sgo/test.Field4"
+	runnerName := "github.com/nanomarkup/sgo/test.RunnerImpl"
+	items[appName] = [][]string{{"entry", itemPath}, {"autowire", "true"}}
+	items[itemPath] = [][]string{
+		{"Field4", "*" + f4Name},
+	}
+	items[f4Name] = [][]string{
+		{"Name", "\"Hello\""},
+	}
+	items[runnerName] = [][]string{}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	deps, err := os.ReadFile(filepath.Join(s.name, depsFileName))
+	c.Assert(err, check.IsNil)
+	c.Assert(strings.Count(string(deps), "v.Runner = UseTestRunnerImplRef()"), check.Equals, 2)
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeAutowireErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	runnerName := "github.com/nanomarkup/sgo/test.RunnerImpl"
+	runner2Name := "github.com/nanomarkup/sgo/test.RunnerImpl2"
+	items[appName] = [][]string{{"entry", itemPath}, {"autowire", "true"}}
+	items[runnerName] = [][]string{}
+	items[runner2Name] = [][]string{}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(ImplementationIsAmbiguousF, "Runner", itemPath, runnerName+", "+runner2Name)))
+}
+
+func (s *sgoSuite) TestCodeCollections(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	items[itemPath] = [][]string{
+		{"Runners", "[jobs]*..."},
+		{"Greeters", "[greeters]*..."},
+	}
+	items["[jobs]github.com/nanomarkup/sgo/test.RunnerImpl2"] = [][]string{}
+	items["[jobs]github.com/nanomarkup/sgo/test.RunnerImpl"] = [][]string{}
+	items["[greeters]github.com/nanomarkup/sgo/test.GreeterImpl"] = [][]string{}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.IsNil)
+	deps, err := os.ReadFile(filepath.Join(s.name, depsFileName))
+	c.Assert(err, check.IsNil)
+	c.Assert(string(deps), check.Matches, "(?s).*v.Runners = \\[\\]p\\d+.Runner{UsejobsGroupTestRunnerImplRef\\(\\), UsejobsGroupTestRunnerImpl2Ref\\(\\)}.*")
+	c.Assert(s.t.Run(fmt.Sprintf("%s-Build", getTestName(c)), func(t *testing.T) {
+		if err := s.builder.Build(s.name); err != nil {
+			t.Error(err)
+		}
+	}), check.Equals, true)
+}
+
+func (s *sgoSuite) TestCodeCollectionsErrors(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	f2Name := "github.com/nanomarkup/sgo/test.Field2"
+	items[itemPath] = [][]string{
+		{"Runner", "[jobs]*..."},
+	}
+	items["[jobs]github.com/nanomarkup/sgo/test.RunnerImpl"] = [][]string{}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(TypeDoesNotSupportedF, "[jobs]*...")))
+	items[itemPath] = [][]string{
+		{"Runners", "[jobs]*..."},
+	}
+	items["[jobs]"+f2Name] = [][]string{}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(MethodIsMissingF, "Run", f2Name)))
+	// the value of the item does not implement the interface by the methods with the pointer receiver
+	delete(items, "[jobs]"+f2Name)
+	items[itemPath] = [][]string{
+		{"Runners", "[jobs]..."},
+	}
+	s.coder.Init(items)
+	c.Assert(s.coder.Generate(s.name), check.ErrorMatches, regexp.QuoteMeta(fmt.Sprintf(MethodHasPointerReceiverF, "Run", "[jobs]github.com/nanomarkup/sgo/test.RunnerImpl")))
+}
+
+func (s *sgoSuite) TestCodeAnalyze(c *check.C) {
+	defer s.clean()
+	items := s.copyItems()
+	f2Name := "github.com/nanomarkup/sgo/test.Field2"
+	f3Name := "github.com/nanomarkup/sgo/test.Field3"
+	f4Name := "github.com/nanomarkup/sgo/test.Field4"
+	dualName := "github.com/nanomarkup/sgo/test.Dual"
+	items[appsItemName] = [][]string{{appName}, {"missing"}, {"broken"}, {"unknown"}, {"empty"}, {"partial"}}
+	items["broken"] = [][]string{{"entry", "github.com/nanomarkup/sgo/test.NewField2(\"a\""}}
+	items["unknown"] = [][]string{{"entry", "github.com/nanomarkup/sgo/test.Field9"}}
+	// the undeclared struct item is created by the generator
+	items["empty"] = [][]string{{"entry", "github.com/nanomarkup/sgo/test.Field1"}}
+	// the application cannot be resolved with one profile only
+	items["partial"] = [][]string{{"entry", dualName}}
+	items["[Bye]"+dualName] = [][]string{
+		{"Field2", "github.com/nanomarkup/sgo/test.NewMissing()"},
+	}
+	items[itemPath] = [][]string{
+		{"Field2", "[Hi]*" + f2Name},
+		{"Field4", "*" + f4Name},
+	}
+	items[f2Name] = [][]string{
+		{"Name", "\"Hello\""},
+	}
+	items["[Hi]"+f2Name] = [][]string{
+		{"extends"},
+	}
+	// the item of the profile is used if the profile is selected
+	items[f4Name] = [][]string{
+		{"Name", "\"Hello\""},
+	}
+	items["[Hi]"+f4Name] = [][]string{
+		{"Name", "\"Hi\""},
+	}
+	items[f3Name] = [][]string{
+		{"Field", "github.com/nanomarkup/sgo/test.NewField1()"},
+	}
+	items["[Bye]"+f3Name] = [][]string{
+		{"extends"},
+	}
+	s.coder.Init(items)
+	report, err := s.coder.Analyze()
+	c.Assert(err, check.IsNil)
+	c.Assert(report.Unused, check.DeepEquals, []string{"[Bye]" + f3Name, f3Name})
+	c.Assert(report.Unresolved, check.HasLen, 4)
+	c.Assert(report.Unresolved[0], check.DeepEquals, UnresolvedApp{Application: "missing", Error: fmt.Sprintf(ItemIsMissingF, "missing")})
+	c.Assert(report.Unresolved[1].Application, check.Equals, "broken")
+	c.Assert(report.Unresolved[2].Application, check.Equals, "unknown")
+	c.Assert(report.Unresolved[2].Entry, check.Equals, "github.com/nanomarkup/sgo/test.Field9")
+	c.Assert(report.Unresolved[3], check.DeepEquals, UnresolvedApp{
+		Application: "partial",
+		Entry:       dualName,
+		Profile:     "Bye",
+		Error:       fmt.Sprintf(TypeIsMissingF, "github.com/nanomarkup/sgo/test.NewMissing"),
+	})
+}
 
 // func (s *sgoSuite) TestCodeSgoUsingGoModules(c *check.C) {
 // 	m := dl.Manager{}
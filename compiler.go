@@ -1,30 +1,47 @@
 package sgo
 
 import (
+	"go/token"
 	"reflect"
 	"strings"
 )
 
-func (c *compiler) getTypeInfo(list items, wd string) ([]typeInfo, error) {
+func (c *compiler) getTypeInfo(list items, wd string, cache *typeCache) ([]typeInfo, error) {
+	loader := newTypeLoader(wd, cache)
+	defer loader.cache.save()
+	return c.loadTypeInfo(list, loader)
+}
+
+// loadTypeInfo returns the details of the types of the items, the loader keeps the loaded packages
+func (c *compiler) loadTypeInfo(list items, loader *typeLoader) ([]typeInfo, error) {
 	id := ""
 	kind := reflect.Interface
 	done := map[string]bool{}
 	input := []typeInfo{}
 	for _, x := range list {
-		// the struct and interface types are supported only
+		// the struct types and values are supported only
+		var value *field
 		switch x.kind {
-		case itemKind.Struct:
+		case itemKind.Struct, itemKind.Inline:
 			kind = reflect.Struct
+		case itemKind.Value:
+			kind = reflect.Invalid
+			value = &field{}
+		case itemKind.Func:
+			// the package-level functions are processed to check the calls
+			if x.path+x.pkg == "" {
+				continue
+			}
+			kind = reflect.Func
+			value = &field{}
 		default:
 			continue
 		}
-		id = x.original
-		// remove the group name from the original
-		if x.group != "" {
-			id = id[len(x.group)+2:]
-		}
 		// process only once as a simple type
-		id = strings.TrimPrefix(id, "*")
+		id = getTypeId(&x)
+		if x.kind == itemKind.Func {
+			id = getFuncId(&x)
+		}
 		// do not process the same item again
 		if _, found := done[id]; found {
 			continue
@@ -34,19 +51,19 @@ func (c *compiler) getTypeInfo(list items, wd string) ([]typeInfo, error) {
 		input = append(input, typeInfo{
 			Id:      id,
 			Kind:    kind,
-			Name:    x.name,
+			Name:    getTypeName(&x),
 			PkgPath: strings.TrimPrefix(x.path+x.pkg, "*"),
+			Value:   value,
 		})
 	}
 	if len(input) == 0 {
 		return []typeInfo{}, nil
-	} else {
-		return c.processTypes(input, done, wd)
 	}
+	return c.processTypes(input, done, loader)
 }
 
-func (c *compiler) processTypes(list []typeInfo, done map[string]bool, wd string) ([]typeInfo, error) {
-	curr, err := getTypeInfo(wd, list)
+func (c *compiler) processTypes(list []typeInfo, done map[string]bool, loader *typeLoader) ([]typeInfo, error) {
+	curr, err := loader.getTypeInfo(list)
 	if err != nil {
 		return nil, err
 	}
@@ -54,77 +71,79 @@ func (c *compiler) processTypes(list []typeInfo, done map[string]bool, wd string
 	next := []typeInfo{}
 	for _, x := range curr {
 		// process all fields
-		if x.Fields != nil {
-			for _, f := range x.Fields {
-				// the struct and interface typers are supported only
-				if (f.Kind != reflect.Struct && f.Kind != reflect.Interface) || f.Id == "." || f.PkgPath == "" {
-					continue
-				}
-				// do not process the same item again
-				if _, found := done[f.Id]; found {
-					continue
-				} else {
-					done[f.Id] = true
-				}
-				next = append(next, typeInfo{
-					Id:      f.Id,
-					Kind:    f.Kind,
-					Name:    f.TypeName,
-					PkgPath: f.PkgPath,
-				})
-			}
+		for _, f := range x.Fields {
+			c.appendType(&next, f, done)
 		}
 		// process all methods
-		if x.Methods != nil {
-			for _, m := range x.Methods {
-				// input params
-				for _, f := range m.In {
-					// the struct and interface types are supported only
-					if (f.Kind != reflect.Struct && f.Kind != reflect.Interface) || f.Id == "." || f.PkgPath == "" {
-						continue
-					}
-					// do not process the same item again
-					if _, found := done[f.Id]; found {
-						continue
-					} else {
-						done[f.Id] = true
-					}
-					next = append(next, typeInfo{
-						Id:      f.Id,
-						Kind:    f.Kind,
-						Name:    f.TypeName,
-						PkgPath: f.PkgPath,
-					})
-				}
-				// output params
-				for _, f := range m.Out {
-					// the struct and interface types are supported only
-					if (f.Kind != reflect.Struct && f.Kind != reflect.Interface) || f.Id == "." || f.PkgPath == "" {
-						continue
-					}
-					// do not process the same item again
-					if _, found := done[f.Id]; found {
-						continue
-					} else {
-						done[f.Id] = true
-					}
-					next = append(next, typeInfo{
-						Id:      f.Id,
-						Kind:    f.Kind,
-						Name:    f.TypeName,
-						PkgPath: f.PkgPath,
-					})
-				}
+		for _, m := range x.Methods {
+			// input params
+			for _, f := range m.In {
+				c.appendType(&next, f, done)
+			}
+			// output params
+			for _, f := range m.Out {
+				c.appendType(&next, f, done)
 			}
 		}
+		// process the type of a value
+		if x.Value != nil {
+			c.appendType(&next, *x.Value, done)
+		}
+		// process the element and the key of a named composite type
+		if x.Elem != nil {
+			c.appendType(&next, *x.Elem, done)
+		}
+		if x.Key != nil {
+			c.appendType(&next, *x.Key, done)
+		}
 	}
 	if len(next) > 0 {
 		// recursion...
-		n, e := c.processTypes(next, done, wd)
+		n, e := c.processTypes(next, done, loader)
 		if e != nil {
-			return nil, err
+			return nil, e
 		}
 		curr = append(curr, n...)
 	}
 	return curr, nil
 }
+
+func (c *compiler) appendType(list *[]typeInfo, f field, done map[string]bool) {
+	// the unexported embedded types are processed to promote their fields
+	embedded := f.Embedded
+	// process the element type of arrays, slices, maps, channels and pointers
+	if f.Kind == reflect.Array || f.Kind == reflect.Slice || f.Kind == reflect.Map || f.Kind == reflect.Chan || f.Kind == reflect.Ptr {
+		for f.Elem != nil && f.Id == "." {
+			if f.Key != nil {
+				c.appendType(list, *f.Key, done)
+			}
+			f = *f.Elem
+		}
+	}
+	// process the parameters of unnamed functions
+	if f.Kind == reflect.Func && f.Id == "." {
+		for _, p := range f.In {
+			c.appendType(list, p, done)
+		}
+		for _, p := range f.Out {
+			c.appendType(list, p, done)
+		}
+		return
+	}
+	// the exported named types are supported only
+	if f.Id == "." || f.PkgPath == "" || (!token.IsExported(f.TypeName) && !embedded) {
+		return
+	}
+	// do not process the same item again
+	if _, found := done[f.Id]; found {
+		return
+	} else {
+		done[f.Id] = true
+	}
+	*list = append(*list, typeInfo{
+		Id:      f.Id,
+		Kind:    f.Kind,
+		Name:    f.TypeName,
+		PkgPath: f.PkgPath,
+	})
+}
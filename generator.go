@@ -3,6 +3,9 @@ package sgo
 import (
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type structBegGen struct {
@@ -11,6 +14,8 @@ type structBegGen struct {
 
 type structEndGen struct {
 	next structGenerator
+	// the components are started and stopped by the application
+	lifecycle bool
 }
 
 type structCreateGen struct {
@@ -19,31 +24,69 @@ type structCreateGen struct {
 
 type structInitGen struct {
 	next structGenerator
+	// the statements which check the errors of fallible calls and register the components before the assignment
+	checks []string
+	vars   int
+	// the components are started and stopped by the application
+	lifecycle bool
 }
 
 func (g *generator) createStruct(it item, types []typeInfo, imp imports, adapter *adapter) ([]string, error) {
 	code := []string{}
 	if err := g.structGenerator.execute(it, types, imp, &code, adapter); err != nil {
 		return nil, err
+	}
+	if it.singleton {
+		return g.shareStruct(it, imp, code)
 	} else {
 		return code, nil
 	}
 }
 
-func (s *structBegGen) execute(it item, types []typeInfo, imp imports, code *[]string, adapter *adapter) error {
+// shareStruct wraps the body of the "Use" function to create the instance only once
+func (g *generator) shareStruct(it item, imp imports, code []string) ([]string, error) {
+	typeName, err := getItemDefine(imp, &it)
+	if err != nil {
+		return nil, err
+	}
+	return getSharedCode(imp, getFuncName(&it, it.ref), typeName, it.fallible, code), nil
+}
+
+// shareValue returns the "Use" function of the shared item which returns a copy of the instance kept by the reference
+func (g *generator) shareValue(it item, imp imports) ([]string, error) {
+	typeName, err := getItemDefine(imp, &it)
+	if err != nil {
+		return nil, err
+	}
 	funcName := getFuncName(&it, false)
-	fullNameDefine := it.name
-	if len(it.path) > 0 {
-		if it.path[0] == '*' {
-			alias := string(appendImport(imp, it.path[1:]+it.pkg))
-			funcName = funcName + GenRefSufix
-			fullNameDefine = fmt.Sprintf("*%s.%s", alias, it.name)
-		} else {
-			alias := string(appendImport(imp, it.path+it.pkg))
-			fullNameDefine = fmt.Sprintf("%s.%s", alias, it.name)
-		}
+	refName := getFuncName(&it, true)
+	res := []string{}
+	if it.fallible {
+		res = append(res, fmt.Sprintf("func %s() (%s, error) {\n", funcName, typeName))
+		res = append(res, fmt.Sprintf("\tv, err := %s()\n", refName))
+		res = append(res, "\tif err != nil {\n")
+		res = append(res, fmt.Sprintf("\t\treturn %s{}, err\n", typeName))
+		res = append(res, "\t}\n")
+		res = append(res, "\treturn *v, nil\n")
+	} else {
+		res = append(res, fmt.Sprintf("func %s() %s {\n", funcName, typeName))
+		res = append(res, fmt.Sprintf("\treturn *%s()\n", refName))
+	}
+	res = append(res, "}\n\n")
+	return res, nil
+}
+
+func (s *structBegGen) execute(it item, types []typeInfo, imp imports, code *[]string, adapter *adapter) error {
+	funcName := getFuncName(&it, it.ref)
+	fullNameDefine, err := getItemDefine(imp, &it)
+	if err != nil {
+		return err
+	}
+	if it.fallible {
+		*code = append(*code, fmt.Sprintf("func %s() (%s, error) {\n", funcName, fullNameDefine))
+	} else {
+		*code = append(*code, fmt.Sprintf("func %s() %s {\n", funcName, fullNameDefine))
 	}
-	*code = append(*code, fmt.Sprintf("func %s() %s {\n", funcName, fullNameDefine))
 	if s.next != nil {
 		return s.next.execute(it, types, imp, code, adapter)
 	} else {
@@ -52,14 +95,18 @@ func (s *structBegGen) execute(it item, types []typeInfo, imp imports, code *[]s
 }
 
 func (s *structCreateGen) execute(it item, types []typeInfo, imp imports, code *[]string, adapter *adapter) error {
-	returnName := it.name
+	args, err := getTypeArgs(imp, &it)
+	if err != nil {
+		return err
+	}
+	returnName := it.name + args
 	if len(it.path) > 0 {
 		if it.ref {
 			alias := string(appendImport(imp, it.path[1:]+it.pkg))
-			returnName = fmt.Sprintf("&%s.%s", alias, it.name)
+			returnName = fmt.Sprintf("&%s.%s%s", alias, it.name, args)
 		} else {
 			alias := string(appendImport(imp, it.path+it.pkg))
-			returnName = fmt.Sprintf("%s.%s", alias, it.name)
+			returnName = fmt.Sprintf("%s.%s%s", alias, it.name, args)
 		}
 	}
 	*code = append(*code, fmt.Sprintf("\tv := %s{}\n", returnName))
@@ -70,22 +117,60 @@ func (s *structCreateGen) execute(it item, types []typeInfo, imp imports, code *
 	}
 }
 
-func (s *structInitGen) genFunc(f *item) (string, error) {
-	code := f.name + "("
+func (s *structInitGen) genFunc(types []typeInfo, imp imports, adapter *adapter, f *item) (string, error) {
+	args, err := getTypeArgs(imp, f)
+	if err != nil {
+		return "", err
+	}
+	// check the number of arguments if the signature is known
+	sig := getFuncInfo(types, f)
+	if sig != nil {
+		count := len(sig.In)
+		if sig.Variadic && len(f.deps) < count-1 {
+			return "", fmt.Errorf(FuncArgsAreIncorrectF, f.original, len(f.deps), fmt.Sprintf("at least %d", count-1))
+		} else if !sig.Variadic && len(f.deps) != count {
+			return "", fmt.Errorf(FuncArgsAreIncorrectF, f.original, len(f.deps), strconv.Itoa(count))
+		}
+	}
+	code := f.name + args + "("
 	for i, n := range f.deps {
 		d := n.item
-		// process all parameters IN PROGRESS
 		parameter := ""
-		switch d.kind {
-		case itemKind.Func:
-			parameter = d.name
-		case itemKind.Struct:
-			funcName := getFuncName(d, len(d.path) > 0 && d.path[0] == '*')
-			parameter = funcName + "()"
-		case itemKind.String, itemKind.Number, itemKind.Boolean:
-			parameter = d.original
-		default:
-			return "", fmt.Errorf(TypeDoesNotSupportedF, d.original)
+		if sig != nil {
+			// the argument is checked against the parameter type like a field
+			p := sig.In[min(i, len(sig.In)-1)]
+			if sig.Variadic && i >= len(sig.In)-1 && p.Elem != nil {
+				p = *p.Elem
+			}
+			if parameter, err = s.genValue(types, imp, adapter, &p, d); err != nil {
+				return "", fmt.Errorf(FuncArgIsIncorrectF, i+1, f.original, err)
+			}
+		} else {
+			switch d.kind {
+			case itemKind.Func:
+				// the nested function is called as a parameter
+				alias := string(appendImport(imp, d.path+d.pkg))
+				if alias != "" {
+					alias += "."
+				}
+				call, e := s.genFunc(types, imp, adapter, d)
+				if e != nil {
+					return "", fmt.Errorf(FuncArgIsIncorrectF, i+1, f.original, e)
+				}
+				parameter = alias + call
+			case itemKind.Struct, itemKind.Inline:
+				funcName := getFuncName(d, len(d.path) > 0 && d.path[0] == '*')
+				parameter = funcName + "()"
+				if d.fallible {
+					parameter = s.genCheck(imp, parameter, "")
+				}
+			case itemKind.Value:
+				parameter = fmt.Sprintf("%s.%s", appendImport(imp, d.path+d.pkg), d.name)
+			case itemKind.String, itemKind.Number, itemKind.Boolean:
+				parameter = d.original
+			default:
+				return "", fmt.Errorf(FuncArgIsIncorrectF, i+1, f.original, fmt.Errorf(TypeDoesNotSupportedF, d.original))
+			}
 		}
 
 		if i == 0 {
@@ -97,85 +182,408 @@ func (s *structInitGen) genFunc(f *item) (string, error) {
 	return code + ")", nil
 }
 
+// genValue returns an expression of the item which is assigned to the field
+func (s *structInitGen) genValue(types []typeInfo, imp imports, adapter *adapter, f *field, d *item) (string, error) {
+	switch d.kind {
+	case itemKind.Func:
+		alias := string(appendImport(imp, d.path+d.pkg))
+		if alias != "" {
+			alias += "."
+		}
+		sig := getFuncInfo(types, d)
+		if f.Kind == reflect.Func && !d.exec {
+			// it is a reference to a func then just return it as is
+			if sig != nil && !isAssignableType(f, sig) {
+				return "", fmt.Errorf(FuncIsIncompatibleF, d.original, getTypeDesc(f))
+			}
+			args, err := getTypeArgs(imp, d)
+			if err != nil {
+				return "", err
+			}
+			return alias + d.name + args, nil
+		}
+		code, err := s.genFunc(types, imp, adapter, d)
+		if err != nil {
+			return "", err
+		}
+		// the single result of the function or the result with an error is assigned
+		if sig != nil && ((len(sig.Out) != 1 && !isFallibleFunc(sig)) || !s.isAssignable(types, adapter, f, &sig.Out[0])) {
+			return "", fmt.Errorf(FuncResultIsIncompatibleF, d.original, getTypeDesc(f))
+		}
+		var lc *lifecycle
+		if s.lifecycle {
+			lc = getResultLifecycle(types, sig)
+		}
+		if lc != nil && lc.pointer && sig.Out[0].Kind != reflect.Ptr && sig.Out[0].Kind != reflect.Interface {
+			return "", fmt.Errorf(ComponentIsCopiedF, d.original)
+		}
+		if sig != nil && isFallibleFunc(sig) {
+			code = s.genCheck(imp, alias+code, d.original)
+		} else if lc != nil {
+			code = s.genVar(alias + code)
+		} else {
+			return alias + code, nil
+		}
+		// the created component is started and stopped together with the application
+		if lc != nil {
+			s.checks = append(s.checks, getComponentCode(lc, d.original, code))
+		}
+		return code, nil
+	case itemKind.Struct, itemKind.Inline:
+		if f.Kind == reflect.Func {
+			return s.genProvider(types, imp, adapter, f, d)
+		}
+		funcName, err := s.genUse(types, adapter, f, d)
+		if err != nil {
+			return "", err
+		}
+		if d.fallible {
+			return s.genCheck(imp, funcName+"()", ""), nil
+		}
+		return funcName + "()", nil
+	case itemKind.Slice, itemKind.Collection:
+		return s.genSlice(types, imp, adapter, f, d)
+	case itemKind.Map:
+		return s.genMap(types, imp, adapter, f, d)
+	case itemKind.Env:
+		return s.genEnv(imp, adapter, f, d)
+	case itemKind.Value:
+		return s.genValueRef(types, imp, adapter, f, d)
+	case itemKind.String, itemKind.Number, itemKind.Boolean:
+		return s.genLiteral(imp, f, d)
+	}
+	return "", fmt.Errorf(TypeDoesNotSupportedF, d.original)
+}
+
+// genCheck assigns the result of the fallible call to a new variable and returns its name,
+// the error is returned by the "Use" function and it is described by the failing component if it is specified
+func (s *structInitGen) genCheck(imp imports, call string, component string) string {
+	s.vars++
+	name := fmt.Sprintf("v%d", s.vars)
+	s.checks = append(s.checks, fmt.Sprintf("\t%s, err := %s\n", name, call))
+	s.checks = append(s.checks, "\tif err != nil {\n")
+	if component == "" {
+		s.checks = append(s.checks, "\t\treturn v, err\n")
+	} else {
+		s.checks = append(s.checks, fmt.Sprintf("\t\treturn v, %s.Errorf(\"%%s: %%w\", %s, err)\n", appendImport(imp, "fmt"), strconv.Quote(component)))
+	}
+	s.checks = append(s.checks, "\t}\n")
+	return name
+}
+
+// genVar assigns the result of the call to a new variable and returns its name
+func (s *structInitGen) genVar(call string) string {
+	s.vars++
+	name := fmt.Sprintf("v%d", s.vars)
+	s.checks = append(s.checks, fmt.Sprintf("\t%s := %s\n", name, call))
+	return name
+}
+
+// genUse returns a name of the "Use" function of the struct item or its adapter to the field type
+func (s *structInitGen) genUse(types []typeInfo, adapter *adapter, f *field, d *item) (string, error) {
+	ref := len(d.path) > 0 && d.path[0] == '*'
+	supported, err := adapter.areTypesCompatible(types, *f, getTypeId(d))
+	if err != nil {
+		return "", err
+	}
+	if supported {
+		// the value does not implement the interface by the methods with the pointer receiver
+		if name := getPointerMethod(types, f, getTypeId(d)); !ref && name != "" {
+			return "", fmt.Errorf(MethodHasPointerReceiverF, name, d.original)
+		}
+		return getFuncName(d, ref), nil
+	}
+	return adapter.adapt(types, *f, d, ref)
+}
+
+// genProvider returns a function which creates the struct item on demand, like "func() T" or "func() (T, error)"
+func (s *structInitGen) genProvider(types []typeInfo, imp imports, adapter *adapter, f *field, d *item) (string, error) {
+	if len(f.In) > 0 || len(f.Out) == 0 || len(f.Out) > 2 || (len(f.Out) == 2 && f.Out[1].Id != errorTypeId) {
+		return "", fmt.Errorf(ProviderIsIncorrectF, f.FieldName, d.original)
+	}
+	funcName, err := s.genUse(types, adapter, &f.Out[0], d)
+	if err != nil {
+		return "", err
+	}
+	typeName, err := getTypeDefine(imp, &f.Out[0])
+	if err != nil {
+		return "", err
+	}
+	if d.fallible {
+		// the error of the item is returned by the provider
+		if len(f.Out) == 1 {
+			return "", fmt.Errorf(ProviderErrorIsMissingF, d.original, f.FieldName)
+		}
+		return fmt.Sprintf("func() (%s, error) { return %s() }", typeName, funcName), nil
+	}
+	if len(f.Out) == 1 {
+		return fmt.Sprintf("func() %s { return %s() }", typeName, funcName), nil
+	}
+	return fmt.Sprintf("func() (%s, error) { return %s(), nil }", typeName, funcName), nil
+}
+
+// genValueRef returns a qualified identifier of the package-level variable or constant
+func (s *structInitGen) genValueRef(types []typeInfo, imp imports, adapter *adapter, f *field, d *item) (string, error) {
+	id := getTypeId(d)
+	info := getType(types, id)
+	if info == nil || info.Value == nil {
+		return "", fmt.Errorf(TypeIsMissingF, id)
+	}
+	v := info.Value
+	args, err := getTypeArgs(imp, d)
+	if err != nil {
+		return "", err
+	}
+	code := fmt.Sprintf("%s.%s%s", appendImport(imp, d.path+d.pkg), d.name, args)
+	switch {
+	case s.isAssignable(types, adapter, f, v):
+		return code, nil
+	case info.Const != "" && isBasicKind(f.Kind) && v.PkgPath == "" && isRepresentable(getConstValue(info.Const), f.Kind):
+		// the constants of basic types are converted to the field type if the value is not changed
+		typeName, err := getTypeDefine(imp, f)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s(%s)", typeName, code), nil
+	}
+	return "", fmt.Errorf(ValueIsIncompatibleF, d.original, f.Kind)
+}
+
+// isAssignable returns true if the value of the type can be assigned to the field as is
+func (s *structInitGen) isAssignable(types []typeInfo, adapter *adapter, f *field, v *field) bool {
+	switch {
+	case f.Kind == reflect.Interface && f.Id == ".":
+		// it is type of interface{}
+		return true
+	case isAssignableType(f, v):
+		return true
+	case f.Kind == reflect.Interface:
+		// the value should implement the interface
+		typeB := v.Id
+		if v.Kind == reflect.Ptr && v.Elem != nil {
+			typeB = v.Elem.Id
+		}
+		supported, err := adapter.areTypesCompatible(types, *f, typeB)
+		if v.Kind != reflect.Ptr && getPointerMethod(types, f, typeB) != "" {
+			return false
+		}
+		return err == nil && supported
+	}
+	return false
+}
+
+// genLiteral returns the literal converted to the named type of the field
+func (s *structInitGen) genLiteral(imp imports, f *field, d *item) (string, error) {
+	if f.Id == durationTypeId && d.kind == itemKind.String {
+		return s.genDuration(imp, f, d)
+	}
+	if err := s.checkLiteral(f, d); err != nil {
+		return "", err
+	}
+	// the unnamed and builtin types do not need a conversion
+	if f.PkgPath == "" || f.Kind == reflect.Interface {
+		return d.original, nil
+	}
+	typeName, err := getTypeDefine(imp, f)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s(%s)", typeName, d.original), nil
+}
+
+// genDuration returns the duration in the largest unit, like "time.Duration(90 * time.Second)"
+func (s *structInitGen) genDuration(imp imports, f *field, d *item) (string, error) {
+	value, err := strconv.Unquote(d.original)
+	if err != nil {
+		return "", fmt.Errorf(DurationIsIncorrectF, d.original)
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return "", fmt.Errorf(DurationIsIncorrectF, d.original)
+	}
+	typeName, err := getTypeDefine(imp, f)
+	if err != nil {
+		return "", err
+	}
+	alias := string(appendImport(imp, "time"))
+	units := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"Hour", time.Hour},
+		{"Minute", time.Minute},
+		{"Second", time.Second},
+		{"Millisecond", time.Millisecond},
+		{"Microsecond", time.Microsecond},
+	}
+	for _, u := range units {
+		if duration%u.value == 0 {
+			return fmt.Sprintf("%s(%d * %s.%s)", typeName, duration/u.value, alias, u.name), nil
+		}
+	}
+	return fmt.Sprintf("%s(%d)", typeName, duration), nil
+}
+
+// checkLiteral checks the literal item can be assigned to the field
+func (s *structInitGen) checkLiteral(f *field, d *item) error {
+	supported := false
+	switch f.Kind {
+	case reflect.Interface:
+		// it is type of interface{}
+		supported = f.Id == "."
+	case reflect.String:
+		supported = d.kind == itemKind.String
+	case reflect.Bool:
+		supported = d.kind == itemKind.Boolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if d.kind == itemKind.Number {
+			_, err := strconv.ParseInt(d.original, 0, 64)
+			if err != nil {
+				_, err = strconv.ParseUint(d.original, 0, 64)
+			}
+			supported = err == nil
+		}
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		supported = d.kind == itemKind.Number
+	}
+	if !supported {
+		return fmt.Errorf(ValueIsIncompatibleF, d.original, f.Kind)
+	}
+	return nil
+}
+
+func (s *structInitGen) genEnv(imp imports, adapter *adapter, f *field, d *item) (string, error) {
+	funcName, bits, result, err := adapter.adaptEnv(f)
+	if err != nil {
+		return "", err
+	}
+	// the default value is a string
+	value := "\"\""
+	if len(d.deps) > 1 {
+		value = d.deps[1].item.original
+		if d.deps[1].item.kind != itemKind.String {
+			value = strconv.Quote(value)
+		}
+	}
+	code := ""
+	if result == "int64" || result == "uint64" || result == "float64" {
+		code = fmt.Sprintf("%s(%s, %s, %d)", funcName, d.deps[0].item.original, value, bits)
+	} else {
+		code = fmt.Sprintf("%s(%s, %s)", funcName, d.deps[0].item.original, value)
+	}
+	// convert the result to the field type
+	if f.TypeName != result {
+		typeName, err := getTypeDefine(imp, f)
+		if err != nil {
+			return "", err
+		}
+		code = fmt.Sprintf("%s(%s)", typeName, code)
+	}
+	return code, nil
+}
+
+func (s *structInitGen) genSlice(types []typeInfo, imp imports, adapter *adapter, f *field, d *item) (string, error) {
+	f = getUnderlying(types, f)
+	if (f.Kind != reflect.Slice && f.Kind != reflect.Array) || f.Elem == nil {
+		return "", fmt.Errorf(TypeDoesNotSupportedF, d.original)
+	}
+	if f.Kind == reflect.Array && len(d.deps) > f.Len {
+		return "", fmt.Errorf(SliceIsOutOfRangeF, d.original)
+	}
+	typeName, err := getTypeDefine(imp, f)
+	if err != nil {
+		return "", err
+	}
+	elems := []string{}
+	for _, e := range d.deps {
+		value, err := s.genValue(types, imp, adapter, f.Elem, e.item)
+		if err != nil {
+			return "", err
+		}
+		elems = append(elems, value)
+	}
+	return fmt.Sprintf("%s{%s}", typeName, strings.Join(elems, ", ")), nil
+}
+
+func (s *structInitGen) genMap(types []typeInfo, imp imports, adapter *adapter, f *field, d *item) (string, error) {
+	f = getUnderlying(types, f)
+	if f.Kind != reflect.Map || f.Key == nil || f.Elem == nil {
+		return "", fmt.Errorf(TypeDoesNotSupportedF, d.original)
+	}
+	typeName, err := getTypeDefine(imp, f)
+	if err != nil {
+		return "", err
+	}
+	elems := []string{}
+	for _, e := range d.deps {
+		k, err := getParser().parseItem(e.name)
+		if err != nil {
+			return "", err
+		}
+		key, err := s.genValue(types, imp, adapter, f.Key, &k)
+		if err != nil {
+			return "", err
+		}
+		value, err := s.genValue(types, imp, adapter, f.Elem, e.item)
+		if err != nil {
+			return "", err
+		}
+		elems = append(elems, fmt.Sprintf("%s: %s", key, value))
+	}
+	return fmt.Sprintf("%s{%s}", typeName, strings.Join(elems, ", ")), nil
+}
+
 func (s *structInitGen) execute(it item, types []typeInfo, imp imports, code *[]string, adapter *adapter) error {
-	var err error
 	var field *field
+	created := map[string]bool{}
+	s.checks = nil
+	s.vars = 0
 	for _, v := range it.deps {
 		switch v.item.kind {
 		case itemKind.Func:
-			alias := string(appendImport(imp, v.item.path+v.item.pkg))
-			if alias != "" {
-				alias += "."
-			}
 			if v.name == "." {
 				// execute the method
-				f, e := s.genFunc(v.item)
+				f, e := s.genFunc(types, imp, adapter, v.item)
 				if e != nil {
 					return e
 				}
+				*code = append(*code, s.checks...)
 				*code = append(*code, fmt.Sprintf("\tv.%s\n", f))
-			} else {
-				if it.group == "" {
-					field, err = getFieldInfo(types, it.original, v.name)
-				} else {
-					field, err = getFieldInfo(types, it.original[len(it.group)+2:], v.name)
-				}
-				if err != nil {
-					return err
-				}
-				switch field.Kind {
-				case reflect.Func:
-					if v.item.exec {
-						f, e := s.genFunc(v.item)
-						if e != nil {
-							return e
-						}
-						*code = append(*code, fmt.Sprintf("\tv.%s = %s%s\n", v.name, alias, f))
-					} else {
-						// it is a reference to a func then just return it as is
-						*code = append(*code, fmt.Sprintf("\tv.%s = %s%s\n", v.name, alias, v.item.name))
-					}
-				case reflect.Struct, reflect.Interface:
-					// if it is a reference to a struct then perform the function
-					f, e := s.genFunc(v.item)
-					if e != nil {
-						return e
-					}
-					*code = append(*code, fmt.Sprintf("\tv.%s = %s%s\n", v.name, alias, f))
-				default:
-					return fmt.Errorf(TypeDoesNotSupportedF, v.item.original)
-				}
-			}
-		case itemKind.Struct:
-			typeId1 := it.original
-			if typeId1[0] == '*' {
-				typeId1 = typeId1[1:]
-			}
-			typeId2 := v.item.original
-			if v.item.group != "" {
-				typeId2 = typeId2[len(v.item.group)+2:]
+				s.checks = nil
+				continue
 			}
-			if typeId2[0] == '*' {
-				typeId2 = typeId2[1:]
-			}
-			supported, err := adapter.areTypesCompatible(types, typeId1, v.name, typeId2)
+			fallthrough
+		case itemKind.Struct, itemKind.Inline, itemKind.Slice, itemKind.Map, itemKind.Collection, itemKind.Env, itemKind.Value,
+			itemKind.String, itemKind.Number, itemKind.Boolean:
+			path, err := getFieldPath(types, getTypeId(&it), v.name)
 			if err != nil {
 				return err
 			}
-			ref := len(v.item.path) > 0 && v.item.path[0] == '*'
-			funcName := ""
-			if supported {
-				funcName = getFuncName(v.item, ref)
-				*code = append(*code, fmt.Sprintf("\tv.%s = %s()\n", v.name, funcName))
-			} else {
-				funcName, err = adapter.adapt(types, typeId1, v.name, typeId2, v.item.group, ref)
+			// the embedded pointers which promote the field are created if they are missing
+			selector := "v"
+			for _, e := range path[:len(path)-1] {
+				selector += "." + e.FieldName
+				if e.Kind != reflect.Ptr || e.Elem == nil || created[selector] {
+					continue
+				}
+				typeName, err := getTypeDefine(imp, e.Elem)
 				if err != nil {
 					return err
 				}
-				*code = append(*code, fmt.Sprintf("\tv.%s = %s()\n", v.name, funcName))
+				*code = append(*code, fmt.Sprintf("\tif %s == nil {\n", selector))
+				*code = append(*code, fmt.Sprintf("\t\t%s = &%s{}\n", selector, typeName))
+				*code = append(*code, "\t}\n")
+				created[selector] = true
+			}
+			field = &path[len(path)-1]
+			value, err := s.genValue(types, imp, adapter, field, v.item)
+			if err != nil {
+				return err
 			}
-		case itemKind.String, itemKind.Number, itemKind.Boolean:
-			*code = append(*code, fmt.Sprintf("\tv.%s = %s\n", v.name, v.item.original))
+			*code = append(*code, s.checks...)
+			*code = append(*code, fmt.Sprintf("\tv.%s = %s\n", v.name, value))
+			s.checks = nil
 		}
 	}
 	if s.next != nil {
@@ -186,7 +594,19 @@ func (s *structInitGen) execute(it item, types []typeInfo, imp imports, code *[]
 }
 
 func (s *structEndGen) execute(it item, types []typeInfo, imp imports, code *[]string, adapter *adapter) error {
-	*code = append(*code, "\treturn v\n")
+	// the created component is started and stopped together with the application,
+	// the methods with the pointer receiver are bound to the instance which is not returned by value
+	if lc := getLifecycle(types, getTypeId(&it)); s.lifecycle && lc != nil {
+		if lc.pointer && !it.ref {
+			return fmt.Errorf(ComponentIsCopiedF, it.original)
+		}
+		*code = append(*code, getComponentCode(lc, it.original, "v"))
+	}
+	if it.fallible {
+		*code = append(*code, "\treturn v, nil\n")
+	} else {
+		*code = append(*code, "\treturn v\n")
+	}
 	*code = append(*code, "}\n\n")
 	if s.next != nil {
 		return s.next.execute(it, types, imp, code, adapter)
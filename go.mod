@@ -1,14 +1,15 @@
 module github.com/nanomarkup/sgo
 
-go 1.21.6
+go 1.22.0
 
 require (
 	github.com/dastoori/higgs v1.1.0
 	github.com/hashicorp/go-hclog v1.6.3
 	github.com/hashicorp/go-plugin v1.6.1
-	github.com/mitchellh/go-ps v1.0.0
 	github.com/spf13/cobra v1.8.1
-	golang.org/x/text v0.17.0
+	golang.org/x/mod v0.21.0
+	golang.org/x/text v0.19.0
+	golang.org/x/tools v0.26.0
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c
 )
 
@@ -24,8 +25,9 @@ require (
 	github.com/mitchellh/go-testing-interface v0.0.0-20171004221916-a61a99592b77 // indirect
 	github.com/oklog/run v1.0.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	golang.org/x/net v0.17.0 // indirect
-	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/net v0.30.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20230711160842-782d3b101e98 // indirect
 	google.golang.org/grpc v1.58.3 // indirect
 	google.golang.org/protobuf v1.31.0 // indirect
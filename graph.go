@@ -0,0 +1,165 @@
+// Copyright 2022 Vitalii Noha vitalii.noga@gmail.com. All rights reserved.
+
+package sgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func getGraph(application, entry, profile string, list items, types []typeInfo) (*graph, error) {
+	g := &graph{
+		Application: application,
+		Entry:       entry,
+		Profile:     profile,
+		Nodes:       []graphNode{},
+		Edges:       []graphEdge{},
+	}
+	for name, it := range list {
+		node := graphNode{
+			Id:        name,
+			Kind:      getKindName(it.kind),
+			Group:     it.group,
+			Ref:       it.ref,
+			Singleton: it.singleton,
+		}
+		switch it.kind {
+		case itemKind.Struct, itemKind.Inline, itemKind.Value:
+			node.Type = getTypeId(&it)
+		case itemKind.String, itemKind.Number, itemKind.Boolean:
+			node.Value = it.original
+		}
+		g.Nodes = append(g.Nodes, node)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].Id < g.Nodes[j].Id
+	})
+	// the adapters are only named and not generated
+	a := adapter{imports: imports{}}
+	if err := g.addEdges(entry, nil, list, types, &a, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// addEdges adds dependencies of the item, the field is a type of the item if it is known
+func (g *graph) addEdges(name string, f *field, list items, types []typeInfo, a *adapter, done map[string]bool) error {
+	it, found := list[name]
+	if !found || done[name] {
+		return nil
+	}
+	done[name] = true
+	var err error
+	for i, d := range it.deps {
+		var df *field
+		edge := graphEdge{From: name, To: d.item.original, Field: d.name}
+		switch it.kind {
+		case itemKind.Struct, itemKind.Inline:
+			if d.name != "." {
+				if df, err = getFieldInfo(types, getTypeId(&it), d.name); err != nil {
+					return err
+				}
+			}
+		case itemKind.Slice, itemKind.Map, itemKind.Collection:
+			if f != nil {
+				df = getUnderlying(types, f).Elem
+			}
+		case itemKind.Func, itemKind.Env:
+			// the parameters are numbered like elements of slices
+			edge.Field = strconv.Itoa(i)
+		}
+		if df != nil && (d.item.kind == itemKind.Struct || d.item.kind == itemKind.Inline) {
+			if edge.Adapter, err = g.getAdapter(types, a, df, d.item); err != nil {
+				return err
+			}
+		}
+		g.Edges = append(g.Edges, edge)
+		if err = g.addEdges(d.item.original, df, list, types, a, done); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getAdapter returns a name of the adapter function if the struct item is not compatible with the field
+func (g *graph) getAdapter(types []typeInfo, a *adapter, f *field, d *item) (string, error) {
+	if f.Kind == reflect.Func {
+		// it is a provider of the struct item
+		if len(f.Out) == 0 {
+			return "", nil
+		}
+		f = &f.Out[0]
+	}
+	supported, err := a.areTypesCompatible(types, *f, getTypeId(d))
+	if err != nil || supported {
+		return "", err
+	}
+	return a.adapt(types, *f, d, len(d.path) > 0 && d.path[0] == '*')
+}
+
+func (g *graph) getLabel(n *graphNode) string {
+	label := n.Id
+	if n.Singleton {
+		label = fmt.Sprintf("%s (%s)", label, singletonScope)
+	}
+	return label
+}
+
+func (g *graph) dot() string {
+	res := []string{fmt.Sprintf("digraph %s {", strconv.Quote(g.Application))}
+	for _, n := range g.Nodes {
+		shape := "ellipse"
+		switch n.Kind {
+		case "struct", "inline":
+			shape = "box"
+		case "string", "number", "boolean", "value", "env":
+			shape = "plaintext"
+		}
+		res = append(res, fmt.Sprintf("\t%s [label=%s, shape=%s];", strconv.Quote(n.Id), strconv.Quote(g.getLabel(&n)), shape))
+	}
+	for _, e := range g.Edges {
+		if e.Adapter == "" {
+			res = append(res, fmt.Sprintf("\t%s -> %s [label=%s];", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(e.Field)))
+		} else {
+			res = append(res, fmt.Sprintf("\t%s -> %s [label=%s, style=dashed];", strconv.Quote(e.From), strconv.Quote(e.To),
+				strconv.Quote(fmt.Sprintf("%s (%s)", e.Field, e.Adapter))))
+		}
+	}
+	res = append(res, "}\n")
+	return strings.Join(res, "\n")
+}
+
+func (g *graph) mermaid() string {
+	// the mermaid does not support special characters in ids of nodes
+	ids := map[string]string{}
+	escape := strings.NewReplacer("\"", "#quot;", "|", "#124;")
+	res := []string{"graph TD"}
+	for i, n := range g.Nodes {
+		ids[n.Id] = fmt.Sprintf("n%d", i+1)
+		if n.Kind == "struct" || n.Kind == "inline" {
+			res = append(res, fmt.Sprintf("\t%s[\"%s\"]", ids[n.Id], escape.Replace(g.getLabel(&n))))
+		} else {
+			res = append(res, fmt.Sprintf("\t%s(\"%s\")", ids[n.Id], escape.Replace(g.getLabel(&n))))
+		}
+	}
+	for _, e := range g.Edges {
+		if e.Adapter == "" {
+			res = append(res, fmt.Sprintf("\t%s -->|\"%s\"| %s", ids[e.From], escape.Replace(e.Field), ids[e.To]))
+		} else {
+			res = append(res, fmt.Sprintf("\t%s -.->|\"%s (%s)\"| %s", ids[e.From], escape.Replace(e.Field), e.Adapter, ids[e.To]))
+		}
+	}
+	return strings.Join(res, "\n") + "\n"
+}
+
+func (g *graph) json() (string, error) {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,501 @@
+// Copyright 2022 Vitalii Noha vitalii.noga@gmail.com. All rights reserved.
+
+package sgo
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// loadPackages loads the packages, it is replaced by tests to check the loading
+var loadPackages = packages.Load
+
+// newTypeLoader returns the loader which loads the packages once for all types
+func newTypeLoader(wd string, cache *typeCache) *typeLoader {
+	return &typeLoader{
+		wd:    wd,
+		pkgs:  map[string]*types.Package{},
+		ctxt:  types.NewContext(),
+		cache: cache,
+	}
+}
+
+// getTypeInfo returns the details of named types and the types of package-level variables, constants and functions
+func (l *typeLoader) getTypeInfo(list []typeInfo) ([]typeInfo, error) {
+	// collect packages of all types to load them at once
+	paths := map[string]bool{}
+	names := map[string]bool{}
+	cached := map[string]typeInfo{}
+	for _, x := range list {
+		// the named types and values are supported only
+		if x.Name == "" || x.PkgPath == "" {
+			continue
+		}
+		// do not load packages of the cached types
+		if info, found := l.cache.get(x); found {
+			cached[x.Id] = info
+			continue
+		}
+		if x.Value != nil {
+			paths[x.PkgPath] = true
+			// the generic function has type arguments in the name
+			getTypeExpr(x.PkgPath+"."+x.Name, func(path string) string {
+				paths[path] = true
+				return "_"
+			})
+			continue
+		}
+		// the instantiated generic types have type arguments in the name,
+		// check the name before loading packages to skip unsupported types
+		name := x.PkgPath + "." + x.Name
+		if _, err := getTypeExpr(name, func(path string) string {
+			paths[path] = true
+			return "_"
+		}); err == nil {
+			names[name] = true
+		}
+	}
+	if err := l.load(paths); err != nil {
+		return nil, err
+	}
+	res := []typeInfo{}
+	for _, x := range list {
+		if x.Name == "" || x.PkgPath == "" {
+			continue
+		}
+		if info, found := cached[x.Id]; found {
+			res = append(res, info)
+		} else if x.Value != nil {
+			info, err := l.getValue(x)
+			if err != nil {
+				return nil, err
+			}
+			l.cache.put(x, *info)
+			res = append(res, *info)
+		} else if name := x.PkgPath + "." + x.Name; names[name] {
+			t, err := l.lookup(name)
+			if err != nil {
+				return nil, err
+			}
+			info := l.getType(t)
+			l.cache.put(x, info)
+			res = append(res, info)
+		}
+	}
+	return res, nil
+}
+
+// load loads the packages which are not loaded yet
+func (l *typeLoader) load(paths map[string]bool) error {
+	missing := []string{}
+	for path := range paths {
+		if _, found := l.pkgs[path]; !found {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	// the module file is created with all requirements if the module is missing
+	filePath := filepath.Join(l.wd, typesFileName)
+	unit := []string{"package main\n", "import ("}
+	for _, path := range missing {
+		unit = append(unit, fmt.Sprintf("\t_ \"%s\"", path))
+	}
+	unit = append(unit, ")\n")
+	if err := os.WriteFile(filePath, []byte(strings.Join(unit, "\n")), 0644); err != nil {
+		return err
+	}
+	defer os.Remove(filePath)
+	if _, err := goMod(l.wd, "unknown"); err != nil {
+		return err
+	}
+	cfg := &packages.Config{
+		// the packages are checked from sources to do not depend on the format of export data
+		Mode:      packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps,
+		Dir:       l.wd,
+		ParseFile: parseDecls,
+	}
+	pkgs, err := loadPackages(cfg, missing...)
+	if err != nil {
+		return err
+	}
+	for _, pkg := range pkgs {
+		// the errors of declarations are reported, the unused imports are expected because the bodies of functions are skipped
+		for _, e := range pkg.Errors {
+			if e.Kind != packages.TypeError || !isSkippedBodyError(e) {
+				return e
+			}
+		}
+		if pkg.Types == nil {
+			return fmt.Errorf(ErrorOnGettingTypeDetails)
+		}
+		l.addPackage(pkg.Types)
+	}
+	return nil
+}
+
+// parseDecls parses the file without bodies of functions which are not required to get the type details
+func parseDecls(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+	file, err := goparser.ParseFile(fset, filename, src, goparser.SkipObjectResolution)
+	if file != nil {
+		for _, d := range file.Decls {
+			// the body is replaced by an empty loop which terminates the function without any identifiers
+			if fn, ok := d.(*ast.FuncDecl); ok && fn.Body != nil {
+				fn.Body = &ast.BlockStmt{
+					Lbrace: fn.Body.Lbrace,
+					List:   []ast.Stmt{&ast.ForStmt{For: fn.Body.Lbrace, Body: &ast.BlockStmt{}}},
+					Rbrace: fn.Body.Rbrace,
+				}
+			}
+		}
+	}
+	return file, err
+}
+
+// isSkippedBodyError returns true if the type error is caused by the skipped bodies of functions,
+// it is the import which is used in the bodies only
+func isSkippedBodyError(e packages.Error) bool {
+	return strings.Contains(e.Msg, " imported ") && strings.HasSuffix(e.Msg, " not used")
+}
+
+// addPackage keeps the package and all imported packages
+func (l *typeLoader) addPackage(pkg *types.Package) {
+	if pkg == nil || l.pkgs[pkg.Path()] != nil {
+		return
+	}
+	l.pkgs[pkg.Path()] = pkg
+	for _, x := range pkg.Imports() {
+		l.addPackage(x)
+	}
+}
+
+// lookup returns the type by the name which has the reflect format like "*pkg/path.Name[int,string]"
+func (l *typeLoader) lookup(name string) (types.Type, error) {
+	switch {
+	case strings.HasPrefix(name, "*"):
+		elem, err := l.lookup(name[1:])
+		if err != nil {
+			return nil, err
+		}
+		return types.NewPointer(elem), nil
+	case strings.HasPrefix(name, "[]"):
+		elem, err := l.lookup(name[2:])
+		if err != nil {
+			return nil, err
+		}
+		return types.NewSlice(elem), nil
+	case strings.HasPrefix(name, "map["):
+		end := findTypeEnd(name, 3)
+		if end < 0 {
+			return nil, fmt.Errorf(TypeDoesNotSupportedF, name)
+		}
+		key, err := l.lookup(name[4:end])
+		if err != nil {
+			return nil, err
+		}
+		elem, err := l.lookup(name[end+1:])
+		if err != nil {
+			return nil, err
+		}
+		return types.NewMap(key, elem), nil
+	case strings.HasPrefix(name, "["):
+		end := strings.Index(name, "]")
+		if end < 0 {
+			return nil, fmt.Errorf(TypeDoesNotSupportedF, name)
+		}
+		size, err := strconv.ParseInt(name[1:end], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(TypeDoesNotSupportedF, name)
+		}
+		elem, err := l.lookup(name[end+1:])
+		if err != nil {
+			return nil, err
+		}
+		return types.NewArray(elem, size), nil
+	}
+	// it is a named type with optional type arguments
+	args := []types.Type{}
+	if pos := strings.Index(name, "["); pos > -1 {
+		if !strings.HasSuffix(name, "]") {
+			return nil, fmt.Errorf(TypeDoesNotSupportedF, name)
+		}
+		for _, arg := range splitTypeArgs(name[pos+1 : len(name)-1]) {
+			t, err := l.lookup(arg)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, t)
+		}
+		name = name[:pos]
+	}
+	var obj types.Object
+	if pos := strings.LastIndex(name, "."); pos < 0 {
+		obj = types.Universe.Lookup(name)
+	} else if pkg := l.pkgs[name[:pos]]; pkg != nil {
+		obj = pkg.Scope().Lookup(name[pos+1:])
+	}
+	tn, ok := obj.(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf(TypeIsMissingF, name)
+	}
+	if len(args) == 0 {
+		return tn.Type(), nil
+	}
+	t, err := types.Instantiate(l.ctxt, tn.Type(), args, true)
+	if err != nil {
+		return nil, fmt.Errorf(TypeArgsAreIncorrectF, name)
+	}
+	return t, nil
+}
+
+// getType returns the details of the named type
+func (l *typeLoader) getType(t types.Type) typeInfo {
+	pkgPath, name := l.getTypeName(t)
+	info := typeInfo{
+		Id:      fmt.Sprintf("%s.%s", pkgPath, name),
+		Kind:    getReflectKind(t),
+		Name:    name,
+		String:  l.getTypeString(t, false),
+		PkgPath: pkgPath,
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		info.Fields = l.getFields(u)
+		info.Methods = l.getMethods(types.NewPointer(t))
+	case *types.Interface:
+		info.Methods = l.getMethods(t)
+	default:
+		// it is a named type of any other kind
+		f := l.getField("", t, true)
+		info.Elem = f.Elem
+		info.Key = f.Key
+		info.Len = f.Len
+		info.Methods = l.getMethods(types.NewPointer(t))
+	}
+	return info
+}
+
+// getValue returns the type of the package-level variable, constant or function,
+// the untyped constants have the default type and the generic functions are instantiated by type arguments
+func (l *typeLoader) getValue(x typeInfo) (*typeInfo, error) {
+	name := x.Name
+	args := []types.Type{}
+	if pos := strings.Index(name, "["); pos > -1 && strings.HasSuffix(name, "]") {
+		for _, arg := range splitTypeArgs(name[pos+1 : len(name)-1]) {
+			t, err := l.lookup(arg)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, t)
+		}
+		name = name[:pos]
+	}
+	var obj types.Object
+	if pkg := l.pkgs[x.PkgPath]; pkg != nil {
+		obj = pkg.Scope().Lookup(name)
+	}
+	if obj == nil {
+		return nil, fmt.Errorf(TypeIsMissingF, x.Id)
+	}
+	t := types.Default(obj.Type())
+	if sig, ok := t.(*types.Signature); ok && sig.TypeParams().Len() > 0 {
+		if len(args) == 0 {
+			// the type arguments of the generic function are inferred by the compiler
+			return &typeInfo{Id: x.Id, Kind: reflect.Invalid, Value: &field{Id: ".", Kind: reflect.Invalid}}, nil
+		}
+		inst, err := types.Instantiate(l.ctxt, sig, args, true)
+		if err != nil {
+			return nil, fmt.Errorf(TypeArgsAreIncorrectF, x.Id)
+		}
+		t = inst
+	}
+	pkgPath, typeName := l.getTypeName(t)
+	f := l.getField("", t, true)
+	info := &typeInfo{
+		Id:      x.Id,
+		Kind:    getReflectKind(t),
+		Name:    typeName,
+		String:  l.getTypeString(t, false),
+		PkgPath: pkgPath,
+		Value:   &f,
+	}
+	// the value of a constant is kept to check the conversions
+	if c, ok := obj.(*types.Const); ok {
+		info.Const = c.Val().ExactString()
+	}
+	return info, nil
+}
+
+// getField returns the details of the type, the element types are processed for unnamed types only
+func (l *typeLoader) getField(name string, t types.Type, elem bool) field {
+	t = types.Unalias(t)
+	pkgPath, typeName := l.getTypeName(t)
+	f := field{
+		Id:        fmt.Sprintf("%s.%s", pkgPath, typeName),
+		Kind:      getReflectKind(t),
+		TypeName:  typeName,
+		FieldName: name,
+		PkgPath:   pkgPath,
+	}
+	if !elem {
+		return f
+	}
+	var key, value types.Type
+	switch u := t.Underlying().(type) {
+	case *types.Array:
+		f.Len = int(u.Len())
+		value = u.Elem()
+	case *types.Slice:
+		value = u.Elem()
+	case *types.Pointer:
+		value = u.Elem()
+	case *types.Map:
+		key, value = u.Key(), u.Elem()
+	case *types.Chan:
+		f.ChanDir = getChanDir(u.Dir())
+		value = u.Elem()
+	case *types.Signature:
+		f.Variadic = u.Variadic()
+		// the underlying types of named parameters of unnamed functions are processed to check the assignability
+		named := isNamedType(t)
+		for i := 0; i < u.Params().Len(); i++ {
+			p := u.Params().At(i).Type()
+			f.In = append(f.In, l.getField("", p, !named || !isNamedType(p)))
+		}
+		for i := 0; i < u.Results().Len(); i++ {
+			r := u.Results().At(i).Type()
+			f.Out = append(f.Out, l.getField("", r, !named || !isNamedType(r)))
+		}
+	}
+	// do not process the element of named types to avoid the recursion
+	if key != nil {
+		k := l.getField("", key, !isNamedType(key))
+		f.Key = &k
+	}
+	if value != nil {
+		e := l.getField("", value, !isNamedType(value))
+		f.Elem = &e
+	}
+	return f
+}
+
+// getFields returns all fields of the struct including the unexported and embedded ones
+func (l *typeLoader) getFields(t *types.Struct) []field {
+	res := []field{}
+	for i := 0; i < t.NumFields(); i++ {
+		f := t.Field(i)
+		x := l.getField(f.Name(), f.Type(), true)
+		x.Embedded = f.Embedded()
+		res = append(res, x)
+	}
+	return res
+}
+
+// getMethods returns the method set of the type, the pointer receiver is the first input parameter
+func (l *typeLoader) getMethods(t types.Type) []method {
+	res := []method{}
+	_, iface := t.Underlying().(*types.Interface)
+	set := types.NewMethodSet(t)
+	for i := 0; i < set.Len(); i++ {
+		sel := set.At(i)
+		// the unexported methods are available for interfaces only
+		if !iface && !token.IsExported(sel.Obj().Name()) {
+			continue
+		}
+		sig := sel.Type().(*types.Signature)
+		x := method{Name: sel.Obj().Name(), Variadic: sig.Variadic()}
+		// input params
+		if !iface {
+			x.In = append(x.In, l.getField("", t, true))
+			if recv := sel.Obj().Type().(*types.Signature).Recv(); recv != nil {
+				_, x.Pointer = recv.Type().(*types.Pointer)
+			}
+		}
+		for n := 0; n < sig.Params().Len(); n++ {
+			p := sig.Params().At(n).Type()
+			_, name := l.getTypeName(p)
+			x.In = append(x.In, l.getField(name, p, true))
+		}
+		// output params
+		for n := 0; n < sig.Results().Len(); n++ {
+			r := sig.Results().At(n).Type()
+			_, name := l.getTypeName(r)
+			x.Out = append(x.Out, l.getField(name, r, true))
+		}
+		res = append(res, x)
+	}
+	return res
+}
+
+// getTypeName returns the package path and the name of the type like reflect does,
+// the unnamed types have the empty name
+func (l *typeLoader) getTypeName(t types.Type) (string, string) {
+	switch x := types.Unalias(t).(type) {
+	case *types.Named:
+		pkgPath := ""
+		if x.Obj().Pkg() != nil {
+			pkgPath = x.Obj().Pkg().Path()
+		}
+		name := l.getTypeString(x, true)
+		if pkgPath != "" {
+			name = name[len(pkgPath)+1:]
+		}
+		return pkgPath, name
+	case *types.Basic:
+		return "", types.Typ[types.Default(x).(*types.Basic).Kind()].Name()
+	}
+	return "", ""
+}
+
+// getTypeString returns the name of the type qualified by the package path or by the package name
+func (l *typeLoader) getTypeString(t types.Type, path bool) string {
+	switch x := types.Unalias(t).(type) {
+	case *types.Named:
+		name := x.Obj().Name()
+		if pkg := x.Obj().Pkg(); pkg != nil && path {
+			name = pkg.Path() + "." + name
+		} else if pkg != nil {
+			name = pkg.Name() + "." + name
+		}
+		if x.TypeArgs().Len() > 0 {
+			args := []string{}
+			// the type arguments are qualified by the package path always
+			for i := 0; i < x.TypeArgs().Len(); i++ {
+				args = append(args, l.getTypeString(x.TypeArgs().At(i), true))
+			}
+			name = fmt.Sprintf("%s[%s]", name, strings.Join(args, ","))
+		}
+		return name
+	case *types.Basic:
+		return types.Typ[types.Default(x).(*types.Basic).Kind()].Name()
+	case *types.Pointer:
+		return "*" + l.getTypeString(x.Elem(), path)
+	case *types.Slice:
+		return "[]" + l.getTypeString(x.Elem(), path)
+	case *types.Array:
+		return fmt.Sprintf("[%d]%s", x.Len(), l.getTypeString(x.Elem(), path))
+	case *types.Map:
+		return fmt.Sprintf("map[%s]%s", l.getTypeString(x.Key(), path), l.getTypeString(x.Elem(), path))
+	case *types.Interface:
+		if x.Empty() {
+			return "interface {}"
+		}
+	}
+	return types.TypeString(t, func(p *types.Package) string {
+		if path {
+			return p.Path()
+		}
+		return p.Name()
+	})
+}
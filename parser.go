@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 type itemRefParser struct {
@@ -14,10 +15,26 @@ type itemExecParser struct {
 	next itemParser
 }
 
+type itemCollectionParser struct {
+	next itemParser
+}
+
+type itemValueParser struct {
+	next itemParser
+}
+
 type itemGroupParser struct {
 	next itemParser
 }
 
+type itemSliceParser struct {
+	next itemParser
+}
+
+type itemMapParser struct {
+	next itemParser
+}
+
 type itemStrParser struct {
 	next itemParser
 }
@@ -34,10 +51,25 @@ type itemFuncParser struct {
 	next itemParser
 }
 
+type itemEnvParser struct {
+	next itemParser
+}
+
+type itemInlineParser struct {
+	next itemParser
+}
+
 type itemPathParser struct {
 	next itemParser
 }
 
+// tokenizer splits an input into tokens skipping the content of quotes and brackets.
+type tokenizer struct {
+	input string
+	pos   int
+	end   int
+}
+
 func (p *parser) parseItem(input string) (item, error) {
 	it := item{kind: itemKind.None, original: input, deps: deps{}}
 	if err := p.itemParser.execute(input, &it); err != nil {
@@ -55,18 +87,245 @@ func (p *parser) parseFunc(input string) ([]string, error) {
 	if pos < 0 {
 		return nil, fmt.Errorf(FuncBegTokenIsMissing)
 	}
-	input = input[pos+1:]
-	pos = strings.Index(input, ")")
+	t, err := p.parseBlock(input, pos)
+	if err != nil {
+		return nil, err
+	}
+	// read all parameters
+	params := []string{}
+	for t.skip(); !t.eof(); t.skip() {
+		value, err := t.value(",")
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			return nil, t.errorf(FuncParamIsMissingF, len(params))
+		}
+		params = append(params, value)
+		if err = t.separator(','); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
+
+func (p *parser) parseInline(input string) ([][]string, error) {
+	pos := strings.Index(input, "{")
 	if pos < 0 {
-		return nil, fmt.Errorf(FuncEndTokenIsMissing)
+		return nil, fmt.Errorf(InlineBegTokenIsMissing)
 	}
-	input = input[0:pos]
-	input = strings.Trim(input, " ")
-	if input == "" {
-		return []string{}, nil
-	} else {
-		return strings.Split(input, ","), nil
+	t, err := p.parseBlock(input, pos)
+	if err != nil {
+		return nil, err
+	}
+	// read all fields
+	rows := [][]string{}
+	for t.skip(); !t.eof(); t.skip() {
+		name, err := t.next("")
+		if err != nil {
+			return nil, err
+		}
+		value, err := t.value("")
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			return nil, t.errorf(InlineFieldValueIsMissingF, name)
+		}
+		rows = append(rows, []string{name, value})
+	}
+	return rows, nil
+}
+
+func (p *parser) parseSlice(input string) ([]string, error) {
+	t, err := p.parseBlock(input, 0)
+	if err != nil {
+		return nil, err
+	}
+	// read all elements
+	list := []string{}
+	for t.skip(); !t.eof(); t.skip() {
+		value, err := t.value(",")
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			return nil, t.errorf(SliceElementIsMissingF, len(list))
+		}
+		list = append(list, value)
+		if err = t.separator(','); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+func (p *parser) parseMap(input string) ([][]string, error) {
+	t, err := p.parseBlock(input, 0)
+	if err != nil {
+		return nil, err
+	}
+	// read all key/value pairs
+	rows := [][]string{}
+	keys := map[string]bool{}
+	for t.skip(); !t.eof(); t.skip() {
+		key, err := t.next(":,")
+		if err != nil {
+			return nil, err
+		}
+		if keys[key] {
+			return nil, t.errorf(MapKeyIsDuplicatedF, key)
+		}
+		keys[key] = true
+		if t.skip(); t.eof() || t.input[t.pos] != ':' {
+			return nil, t.errorf(MapSeparatorIsMissingF, key)
+		}
+		t.pos++
+		value, err := t.value(",")
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			return nil, t.errorf(MapValueIsMissingF, key)
+		}
+		rows = append(rows, []string{key, value})
+		if err = t.separator(','); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// parseBlock returns a tokenizer of the brackets content which should be at the end of the input
+func (p *parser) parseBlock(input string, pos int) (*tokenizer, error) {
+	t := &tokenizer{input: input, pos: pos, end: len(input)}
+	beg := t.pos + 1
+	if _, err := t.block(); err != nil {
+		return nil, err
+	}
+	end := t.pos - 1
+	if t.skip(); !t.eof() {
+		return nil, t.errorf(TokenIsUnexpectedF, t.input[t.pos])
+	}
+	return &tokenizer{input: input, pos: beg, end: end}, nil
+}
+
+func (t *tokenizer) eof() bool {
+	return t.pos >= t.end
+}
+
+func (t *tokenizer) skip() {
+	for !t.eof() && unicode.IsSpace(rune(t.input[t.pos])) {
+		t.pos++
+	}
+}
+
+// errorf returns an error with the current column of the input
+func (t *tokenizer) errorf(format string, a ...any) error {
+	return fmt.Errorf(SyntaxErrorF, fmt.Sprintf(format, a...), t.pos+1, t.input)
+}
+
+// separator skips the separator of values if it is not the end of the input
+func (t *tokenizer) separator(sep byte) error {
+	if t.skip(); !t.eof() {
+		if t.input[t.pos] != sep {
+			return t.errorf(TokenIsUnexpectedF, t.input[t.pos])
+		}
+		t.pos++
+	}
+	return nil
+}
+
+// next returns the next token which ends on a whitespace or on one of the separators
+func (t *tokenizer) next(separators string) (string, error) {
+	return t.read(separators, false)
+}
+
+// block returns the next token which starts with a bracket and ends on the closing one
+func (t *tokenizer) block() (string, error) {
+	return t.read("", true)
+}
+
+// value returns the next token including the body of an inline item
+func (t *tokenizer) value(separators string) (string, error) {
+	value, err := t.next(separators)
+	if err != nil || value == "" {
+		return value, err
+	}
+	if t.skip(); !t.eof() && t.input[t.pos] == '{' {
+		body, err := t.block()
+		if err != nil {
+			return "", err
+		}
+		value = value + " " + body
+	}
+	return value, nil
+}
+
+func (t *tokenizer) read(separators string, block bool) (string, error) {
+	t.skip()
+	beg := t.pos
+	brackets := []byte{}
+	for !t.eof() {
+		c := t.input[t.pos]
+		switch c {
+		case '"', '`':
+			if block && len(brackets) == 0 {
+				return "", t.errorf(TokenIsUnexpectedF, c)
+			}
+			if err := t.skipQuotes(c); err != nil {
+				return "", err
+			}
+			continue
+		case '(':
+			brackets = append(brackets, ')')
+		case '[':
+			brackets = append(brackets, ']')
+		case '{':
+			brackets = append(brackets, '}')
+		case ')', ']', '}':
+			last := len(brackets) - 1
+			if last < 0 || brackets[last] != c {
+				return "", t.errorf(TokenIsUnexpectedF, c)
+			}
+			brackets = brackets[:last]
+			if block && last == 0 {
+				t.pos++
+				return t.input[beg:t.pos], nil
+			}
+		default:
+			if block && len(brackets) == 0 {
+				return "", t.errorf(TokenIsUnexpectedF, c)
+			}
+			if len(brackets) == 0 && (unicode.IsSpace(rune(c)) || strings.IndexByte(separators, c) > -1) {
+				return t.input[beg:t.pos], nil
+			}
+		}
+		t.pos++
+	}
+	if len(brackets) > 0 {
+		// the function call is not terminated
+		if brackets[len(brackets)-1] == ')' {
+			return "", t.errorf(FuncEndTokenIsMissing)
+		}
+		return "", t.errorf(BracketEndTokenIsMissingF, brackets[len(brackets)-1])
+	}
+	return t.input[beg:t.pos], nil
+}
+
+func (t *tokenizer) skipQuotes(quote byte) error {
+	for t.pos++; !t.eof(); t.pos++ {
+		switch t.input[t.pos] {
+		case '\\':
+			if quote == '"' {
+				t.pos++
+			}
+		case quote:
+			t.pos++
+			return nil
+		}
 	}
+	return t.errorf(QuoteEndTokenIsMissing)
 }
 
 func (p *itemRefParser) execute(input string, item *item) error {
@@ -93,8 +352,67 @@ func (p *itemExecParser) execute(input string, item *item) error {
 	}
 }
 
+func (p *itemCollectionParser) execute(input string, item *item) error {
+	if item.kind == itemKind.None && item.group != "" && strings.TrimPrefix(input, "*") == collectionToken {
+		item.kind = itemKind.Collection
+		item.name = input
+	}
+	if p.next != nil {
+		return p.next.execute(input, item)
+	} else {
+		return nil
+	}
+}
+
+func (p *itemValueParser) execute(input string, item *item) error {
+	if item.kind == itemKind.None && strings.HasPrefix(input, "=") {
+		item.kind = itemKind.Value
+		input = input[1:]
+	}
+	if p.next != nil {
+		return p.next.execute(input, item)
+	} else {
+		return nil
+	}
+}
+
+func (p *itemSliceParser) execute(input string, item *item) error {
+	if item.kind == itemKind.None && strings.HasPrefix(input, "[") {
+		// the group item has a name after the closing bracket
+		t := tokenizer{input: input, end: len(input)}
+		if _, err := t.block(); err == nil {
+			if t.skip(); t.eof() {
+				item.kind = itemKind.Slice
+				item.name = input
+			}
+		}
+	}
+	if p.next != nil {
+		return p.next.execute(input, item)
+	} else {
+		return nil
+	}
+}
+
+func (p *itemMapParser) execute(input string, item *item) error {
+	if item.kind == itemKind.None && strings.HasPrefix(input, "{") {
+		t := tokenizer{input: input, end: len(input)}
+		if _, err := t.block(); err == nil {
+			if t.skip(); t.eof() {
+				item.kind = itemKind.Map
+				item.name = input
+			}
+		}
+	}
+	if p.next != nil {
+		return p.next.execute(input, item)
+	} else {
+		return nil
+	}
+}
+
 func (p *itemGroupParser) execute(input string, item *item) error {
-	if strings.HasPrefix(input, "[") {
+	if item.kind == itemKind.None && strings.HasPrefix(input, "[") {
 		if pos := strings.Index(input, "]"); pos > -1 {
 			item.group = input[1:pos]
 			input = input[len(item.group)+2:]
@@ -162,19 +480,65 @@ func (p *itemFuncParser) execute(input string, item *item) error {
 	}
 }
 
+func (p *itemEnvParser) execute(input string, item *item) error {
+	if item.kind == itemKind.None && strings.HasPrefix(input, envFuncName+"(") {
+		item.kind = itemKind.Env
+		item.name = envFuncName
+	}
+	if p.next != nil {
+		return p.next.execute(input, item)
+	} else {
+		return nil
+	}
+}
+
+func (p *itemInlineParser) execute(input string, item *item) error {
+	if item.kind == itemKind.None {
+		// the body of the inline item should be declared before any function call
+		if pos := strings.Index(input, "{"); pos > 0 {
+			if fpos := strings.Index(input, "("); fpos < 0 || pos < fpos {
+				item.kind = itemKind.Inline
+			}
+		}
+	}
+	if p.next != nil {
+		return p.next.execute(input, item)
+	} else {
+		return nil
+	}
+}
+
 func (p *itemPathParser) execute(input string, item *item) error {
-	if item.kind == itemKind.None || item.kind == itemKind.Func {
+	if item.kind == itemKind.None || item.kind == itemKind.Func || item.kind == itemKind.Inline || item.kind == itemKind.Value {
 		var data []string
 		pathSep := "/"
 		nameSep := "."
-		if item.kind == itemKind.None {
+		head := ""
+		switch item.kind {
+		case itemKind.None:
 			item.kind = itemKind.Struct
-			data = strings.Split(input, pathSep)
-		} else {
+			head = input
+		case itemKind.Value:
+			head = input
+		case itemKind.Func:
 			if pos := strings.Index(input, "("); pos > -1 {
-				data = strings.Split(input[:pos], pathSep)
+				head = input[:pos]
+			}
+		case itemKind.Inline:
+			if pos := strings.Index(input, "{"); pos > -1 {
+				head = strings.TrimSpace(input[:pos])
 			}
 		}
+		// get type arguments of the generic type or function
+		if pos := strings.Index(head, "["); pos > -1 {
+			args, err := p.parseTypeArgs(head[pos:])
+			if err != nil {
+				return fmt.Errorf(TypeArgsAreIncorrectF, input)
+			}
+			item.args = args
+			head = head[:pos]
+		}
+		data = strings.Split(head, pathSep)
 		// get path
 		dataLen := len(data)
 		fullName := data[dataLen-1]
@@ -198,3 +562,19 @@ func (p *itemPathParser) execute(input string, item *item) error {
 		return nil
 	}
 }
+
+// parseTypeArgs returns the type arguments, like "[string, *github.com/x/models.User]",
+// in the format of the reflect package
+func (p *itemPathParser) parseTypeArgs(input string) ([]string, error) {
+	if !strings.HasSuffix(input, "]") {
+		return nil, fmt.Errorf(BracketEndTokenIsMissingF, ']')
+	}
+	args := splitTypeArgs(input[1 : len(input)-1])
+	for i, arg := range args {
+		if arg == "" {
+			return nil, fmt.Errorf(FuncParamIsMissingF, i+1)
+		}
+		args[i] = strings.Join(strings.Fields(arg), "")
+	}
+	return args, nil
+}
@@ -31,6 +31,16 @@ func (c *builderClient) Generate(app string, sources *map[string][][]string) err
 	}, new(interface{}))
 }
 
+func (c *builderClient) Graph(app string, format string, sources *map[string][][]string) (string, error) {
+	var resp string
+	err := c.client.Call("Plugin.Graph", map[string]interface{}{
+		"app":     app,
+		"format":  format,
+		"sources": sources,
+	}, &resp)
+	return resp, err
+}
+
 // server's methods
 
 func (s *builderServer) Build(args map[string]interface{}, resp *interface{}) error {
@@ -45,6 +55,12 @@ func (s *builderServer) Generate(args map[string]interface{}, resp *interface{})
 	return s.Impl.Generate(args["app"].(string), args["sources"].(*map[string][][]string))
 }
 
+func (s *builderServer) Graph(args map[string]interface{}, resp *string) error {
+	graph, err := s.Impl.Graph(args["app"].(string), args["format"].(string), args["sources"].(*map[string][][]string))
+	*resp = graph
+	return err
+}
+
 // The implementation of plugin.Plugin so we can serve/consume this
 //
 // There are two methods: Server must return an RPC server for this plugin
@@ -7,6 +7,7 @@ type Builder interface {
 	Build(app string) error
 	Clean(app string, sources *map[string][][]string) error
 	Generate(app string, sources *map[string][][]string) error
+	Graph(app string, format string, sources *map[string][][]string) (string, error)
 }
 
 type BuilderPlugin struct {
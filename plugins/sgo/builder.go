@@ -29,3 +29,8 @@ func (b *builder) Generate(app string, sources *map[string][][]string) error {
 	}
 	return nil
 }
+
+func (b *builder) Graph(app string, format string, sources *map[string][][]string) (string, error) {
+	b.coder.Init(*sources)
+	return b.coder.Graph(app, format)
+}
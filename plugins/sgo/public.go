@@ -20,6 +20,7 @@ type Coder interface {
 	Init(items map[string][][]string)
 	Clean(appName string) error
 	Generate(appName string) error
+	Graph(appName string, format string) (string, error)
 	SetLogger(logger Logger)
 }
 
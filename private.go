@@ -3,21 +3,21 @@
 package sgo
 
 import (
-	"bufio"
-	"bytes"
-	"encoding/gob"
-	"errors"
 	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
 	"io"
-	"io/ioutil"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
+	"unicode"
 
-	"github.com/mitchellh/go-ps"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -31,12 +31,44 @@ const (
 	appsItemName string = "apps"
 	// entryAttrName constant returns an entry attribute name of the application
 	entryAttrName string = "entry"
+	// profileAttrName constant returns a profile attribute name of the application
+	profileAttrName string = "profile"
+	// profileEnvName constant returns a name of the environment variable with a profile
+	profileEnvName string = "SGO_PROFILE"
+	// extendsAttrName constant returns an attribute name of the item with a name of the base item
+	extendsAttrName string = "extends"
+	// autowireAttrName constant returns an attribute name of the application which enables autowiring of interface fields
+	autowireAttrName string = "autowire"
+	// lifecycleAttrName constant returns an attribute name of the application which enables starting and stopping of components
+	lifecycleAttrName string = "lifecycle"
+	// scopeAttrName constant returns a scope attribute name of the item
+	scopeAttrName string = "scope"
+	// singletonScope constant returns a scope of the item which is created only once
+	singletonScope string = "singleton"
+	// transientScope constant returns a scope of the item which is created for every dependency
+	transientScope string = "transient"
+	// collectionToken constant returns a name of the item which collects all items of the group
+	collectionToken string = "..."
+	// envFuncName constant returns a name of function for reading environment variables
+	envFuncName string = "env"
+	// durationTypeId constant returns an id of the duration type
+	durationTypeId string = "time.Duration"
+	// errorTypeId constant returns an id of the error type
+	errorTypeId string = ".error"
+	// contextTypeId constant returns an id of the context type
+	contextTypeId string = "context.Context"
 	// temporary working folder name
 	workingFolderName string = ".sgo"
 	// Go module file name
 	moduleFileName = "go.mod"
 	// Go checksum file name
 	checksumFileName = "go.sum"
+	// typesFileName constant returns name of file which imports all packages to load
+	typesFileName = "types.go"
+	// cacheFolderName constant returns name of folder in the user cache with the type details
+	cacheFolderName = "sgo"
+	// cacheVersion constant returns version of the cached type details which is changed together with the format
+	cacheVersion = "v6"
 )
 
 type itemParser interface {
@@ -53,13 +85,51 @@ type parser struct {
 
 type compiler struct{}
 
+// typeLoader collects the type details using the static analysis of packages
+type typeLoader struct {
+	wd    string
+	pkgs  map[string]*types.Package
+	ctxt  *types.Context
+	cache *typeCache
+}
+
+// typeCache keeps the type details of packages on disk to reuse them across generations
+type typeCache struct {
+	dir     string
+	modPath string
+	modDir  string
+	version string
+	// the selected versions of required modules with checksums
+	mods map[string]string
+	// the computed keys of packages
+	keys    map[string]string
+	entries map[string]*cacheEntry
+	changed map[string]bool
+}
+
+// cacheEntry contains the cached types of the package which are valid for the same key only
+type cacheEntry struct {
+	Key   string
+	Types map[string]cachedType
+}
+
+// cachedType contains the type details and the keys of all packages it depends on
+type cachedType struct {
+	Info typeInfo
+	Deps map[string]string
+}
+
 type generator struct {
 	structGenerator structGenerator
 }
 
 type adapter struct {
-	code    map[string][]string
+	code map[string][]string
+	// the names of the generated code in the order of generation
+	names   []string
 	imports imports
+	// the components are started and stopped by the application
+	lifecycle bool
 }
 
 type resolver struct {
@@ -67,6 +137,26 @@ type resolver struct {
 	entryPoint  string
 	// item -> dep -> resolver
 	items map[string][][]string
+	// the group name of items which override the ungrouped items
+	profile string
+	// the number of processed inline items
+	inlines int
+	// the items in progress to detect dependency cycles
+	stack []frame
+	// the items which dependencies are used if it is not nil
+	used map[string]bool
+	// the interface fields which are not declared are bound to the unique implementation
+	autowire bool
+	// the cached type details, it is nil if the cache is disabled
+	cache *typeCache
+	// the loader which is shared by resolvers to load the packages once, it is optional
+	loader *typeLoader
+}
+
+// frame is an item in progress and its field which is resolving
+type frame struct {
+	name  string
+	field string
 }
 
 type item struct {
@@ -78,7 +168,51 @@ type item struct {
 	original string
 	ref      bool
 	exec     bool
-	deps     deps
+	inline   int
+	// the item is created only once and shared by all dependencies
+	singleton bool
+	// the creation of the item can fail and the "Use" function returns an error
+	fallible bool
+	// the type arguments of the generic type or function
+	args []string
+	// the import paths of type arguments which are named in full because their package names collide
+	qualified map[string]bool
+	deps      deps
+}
+
+// graph is a resolved dependency graph of the application
+type graph struct {
+	Application string      `json:"application"`
+	Entry       string      `json:"entry"`
+	Profile     string      `json:"profile,omitempty"`
+	Nodes       []graphNode `json:"nodes"`
+	Edges       []graphEdge `json:"edges"`
+}
+
+type graphNode struct {
+	Id        string `json:"id"`
+	Kind      string `json:"kind"`
+	Type      string `json:"type,omitempty"`
+	Group     string `json:"group,omitempty"`
+	Ref       bool   `json:"ref,omitempty"`
+	Singleton bool   `json:"singleton,omitempty"`
+	Value     string `json:"value,omitempty"`
+}
+
+type graphEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Field   string `json:"field"`
+	Adapter string `json:"adapter,omitempty"`
+}
+
+// lifecycle contains the methods which start and stop the component together with the application
+type lifecycle struct {
+	start bool
+	stop  bool
+	close bool
+	// any of the methods has the pointer receiver, the instance must not be copied
+	pointer bool
 }
 
 type dep struct {
@@ -94,12 +228,18 @@ type alias string
 type imports map[string]alias
 
 var itemKind = struct {
-	None    uint
-	Func    uint
-	Struct  uint
-	String  uint
-	Number  uint
-	Boolean uint
+	None       uint
+	Func       uint
+	Struct     uint
+	String     uint
+	Number     uint
+	Boolean    uint
+	Inline     uint
+	Slice      uint
+	Map        uint
+	Env        uint
+	Value      uint
+	Collection uint
 }{
 	0,
 	1,
@@ -107,6 +247,12 @@ var itemKind = struct {
 	3,
 	4,
 	5,
+	6,
+	7,
+	8,
+	9,
+	10,
+	11,
 }
 
 type typeInfo struct {
@@ -117,6 +263,14 @@ type typeInfo struct {
 	PkgPath string
 	Fields  []field
 	Methods []method
+	// the element type, the key type and the length of a named composite type
+	Elem *field
+	Key  *field
+	Len  int
+	// the type of a package-level variable or constant
+	Value *field
+	// the exact value of a constant, it is empty for variables
+	Const string
 }
 
 type field struct {
@@ -125,12 +279,29 @@ type field struct {
 	TypeName  string
 	FieldName string
 	PkgPath   string
+	// the anonymous field of a struct which promotes its fields and methods
+	Embedded bool
+	// the element type of an array, a slice, a map, a channel or a pointer
+	Elem *field
+	// the key type of a map
+	Key *field
+	// the length of an array
+	Len int
+	// the direction of a channel
+	ChanDir reflect.ChanDir
+	// the input and output parameters of a function, the last input parameter of a variadic function is a slice
+	In       []field
+	Out      []field
+	Variadic bool
 }
 
 type method struct {
-	Name string
-	In   []field
-	Out  []field
+	Name     string
+	In       []field
+	Out      []field
+	Variadic bool
+	// the method is declared with the pointer receiver
+	Pointer bool
 }
 
 var (
@@ -144,14 +315,26 @@ func getParser() *parser {
 	newParser.Do(func() {
 		parserInst = &parser{}
 		// the order of parsers is very important!
-		parserInst.itemParser = &itemGroupParser{
-			&itemRefParser{
-				&itemExecParser{
-					&itemStrParser{
-						&itemBooleanParser{
-							&itemNumberParser{
-								&itemFuncParser{
-									&itemPathParser{},
+		parserInst.itemParser = &itemSliceParser{
+			&itemMapParser{
+				&itemGroupParser{
+					&itemRefParser{
+						&itemCollectionParser{
+							&itemExecParser{
+								&itemValueParser{
+									&itemStrParser{
+										&itemBooleanParser{
+											&itemNumberParser{
+												&itemEnvParser{
+													&itemInlineParser{
+														&itemFuncParser{
+															&itemPathParser{},
+														},
+													},
+												},
+											},
+										},
+									},
 								},
 							},
 						},
@@ -179,122 +362,821 @@ func getType(types []typeInfo, id string) *typeInfo {
 	return nil
 }
 
-func getTypeInfo(wd string, list []typeInfo) ([]typeInfo, error) {
-	// process all items
-	main := []string{}
-	imports := map[string]string{}
-	if len(list) > 0 {
-		impId := 0
-		impRef := ""
-		itemId := 0
-		found := false
-		for _, x := range list {
-			// the struct and interface types are supported only
-			if x.Kind != reflect.Struct && x.Kind != reflect.Interface {
-				continue
-			}
-			// update imports
-			itemId++
-			impRef, found = imports[x.PkgPath]
-			if !found {
-				impId++
-				impRef = fmt.Sprintf("i%d", impId)
-				imports[x.PkgPath] = impRef
+// hasTypeInfo returns true if the type info of all struct items and values is collected
+func hasTypeInfo(list items, types []typeInfo) bool {
+	for _, x := range list {
+		switch x.kind {
+		case itemKind.Struct, itemKind.Inline, itemKind.Value:
+			if getType(types, getTypeId(&x)) == nil {
+				return false
 			}
-			main = append(main, genSerializeType(itemId, impRef, x))
 		}
 	}
-	// populate the import section
-	unit := []string{}
-	unit = append(unit, "package main\n")
-	unit = append(unit, `import (
-	"bufio"
-	"bytes"
-	"encoding/gob"
-	"fmt"
-	"os"
-	"reflect"
-	`)
-	if len(imports) > 0 {
-		for k, v := range imports {
-			unit = append(unit, fmt.Sprintf("\t%s \"%s\"", v, k))
+	return true
+}
+
+// getReflectKind returns the kind of the underlying type, the untyped constants have the kind of the default type
+func getReflectKind(t types.Type) reflect.Kind {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch types.Default(u).(*types.Basic).Kind() {
+		case types.Bool:
+			return reflect.Bool
+		case types.Int:
+			return reflect.Int
+		case types.Int8:
+			return reflect.Int8
+		case types.Int16:
+			return reflect.Int16
+		case types.Int32:
+			return reflect.Int32
+		case types.Int64:
+			return reflect.Int64
+		case types.Uint:
+			return reflect.Uint
+		case types.Uint8:
+			return reflect.Uint8
+		case types.Uint16:
+			return reflect.Uint16
+		case types.Uint32:
+			return reflect.Uint32
+		case types.Uint64:
+			return reflect.Uint64
+		case types.Uintptr:
+			return reflect.Uintptr
+		case types.Float32:
+			return reflect.Float32
+		case types.Float64:
+			return reflect.Float64
+		case types.Complex64:
+			return reflect.Complex64
+		case types.Complex128:
+			return reflect.Complex128
+		case types.String:
+			return reflect.String
+		case types.UnsafePointer:
+			return reflect.UnsafePointer
 		}
+	case *types.Pointer:
+		return reflect.Ptr
+	case *types.Slice:
+		return reflect.Slice
+	case *types.Array:
+		return reflect.Array
+	case *types.Map:
+		return reflect.Map
+	case *types.Chan:
+		return reflect.Chan
+	case *types.Signature:
+		return reflect.Func
+	case *types.Struct:
+		return reflect.Struct
+	case *types.Interface:
+		return reflect.Interface
+	}
+	return reflect.Invalid
+}
+
+// getChanDir returns the direction of the channel like reflect does
+func getChanDir(dir types.ChanDir) reflect.ChanDir {
+	switch dir {
+	case types.SendOnly:
+		return reflect.SendDir
+	case types.RecvOnly:
+		return reflect.RecvDir
 	}
-	unit = append(unit, ")\n")
-	// populate the main function
-	unit = append(unit, `func main() {
-	data := []Type{}`)
+	return reflect.BothDir
+}
 
-	if len(main) == 0 {
-		unit = append(unit, "\treturn")
-	} else {
-		unit = append(unit, main...)
+// isNamedType returns true if the type has a name like the named and the basic types
+func isNamedType(t types.Type) bool {
+	switch types.Unalias(t).(type) {
+	case *types.Named, *types.Basic:
+		return true
 	}
-	unit = append(unit, `	serialize(data)
+	return false
 }
-`)
 
-	typesPath := filepath.Join(wd, "types")
-	if len(main) > 0 {
-		unit = append(unit, genSerializeFunc(typesPath))
-		defer func() {
-			os.Remove(typesPath)
-		}()
+// isSameType returns true if both fields have the identical type
+func isSameType(a *field, b *field) bool {
+	if a == nil || b == nil {
+		return a == b
 	}
-	// generate a main unit and run it
-	fp := filepath.Join(wd, "main.go")
-	file, err := os.Create(fp)
-	if err != nil {
-		return nil, err
+	if a.Id != b.Id || a.Kind != b.Kind {
+		return false
+	}
+	// the named types are identical by id
+	if a.Id != "." {
+		return true
 	}
-	defer func() {
-		file.Close()
-		os.Remove(fp)
-	}()
+	if a.Len != b.Len || a.ChanDir != b.ChanDir || a.Variadic != b.Variadic {
+		return false
+	}
+	return isSameType(a.Elem, b.Elem) && isSameType(a.Key, b.Key) && isSameParams(a.In, b.In) && isSameParams(a.Out, b.Out)
+}
 
-	writer := bufio.NewWriter(file)
-	writer.WriteString(strings.Join(unit, "\n"))
-	writer.Flush()
-	// serialize items
-	curr, _ := os.Getwd()
-	os.Chdir(wd)
-	defer os.Chdir(curr)
+// isSameParams returns true if both lists have the identical types
+func isSameParams(a []field, b []field) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !isSameType(&a[i], &b[i]) {
+			return false
+		}
+	}
+	return true
+}
 
-	if _, err = goMod(wd, "unknown"); err != nil {
-		return nil, err
+// isAssignableType returns true if the value of the second type can be assigned to the first one without conversion
+func isAssignableType(a *field, b *field) bool {
+	if isSameType(a, b) {
+		return true
 	}
-	if _, err = goRun(fp); err != nil {
-		return nil, err
+	// the value of the unnamed type is assignable to the named type with the identical underlying type and vice versa
+	if a.Kind == b.Kind && !isBasicKind(a.Kind) && a.Kind != reflect.Interface && (a.Id == ".") != (b.Id == ".") {
+		x, y := *a, *b
+		x.Id, y.Id = ".", "."
+		return isSameType(&x, &y)
+	}
+	// the bidirectional channel is assignable to the directional one with the same element type
+	return a.Kind == reflect.Chan && b.Kind == reflect.Chan && (a.Id == "." || b.Id == ".") &&
+		b.ChanDir == reflect.BothDir && isSameType(a.Elem, b.Elem)
+}
+
+// isBasicKind returns true for the boolean, numeric and string kinds
+func isBasicKind(kind reflect.Kind) bool {
+	return (kind >= reflect.Bool && kind <= reflect.Complex128) || kind == reflect.String
+}
+
+// getConstValue returns the constant from its exact string representation, like "-5", "1/3" or "\"s\""
+func getConstValue(exact string) constant.Value {
+	switch {
+	case exact == "true" || exact == "false":
+		return constant.MakeBool(exact == "true")
+	case strings.HasPrefix(exact, "\""):
+		return constant.MakeFromLiteral(exact, token.STRING, 0)
+	case strings.HasPrefix(exact, "-"):
+		return constant.UnaryOp(token.SUB, getConstValue(exact[1:]), 0)
+	}
+	if num, den, found := strings.Cut(exact, "/"); found {
+		return constant.BinaryOp(getConstValue(num), token.QUO, getConstValue(den))
+	}
+	if v := constant.MakeFromLiteral(exact, token.INT, 0); v.Kind() != constant.Unknown {
+		return v
+	}
+	return constant.MakeFromLiteral(exact, token.FLOAT, 0)
+}
+
+// isRepresentable returns true if the constant can be converted to the basic kind without loss
+func isRepresentable(v constant.Value, kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool:
+		return v.Kind() == constant.Bool
+	case reflect.String:
+		return v.Kind() == constant.String
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, exact := constant.Int64Val(constant.ToInt(v))
+		if !exact {
+			return false
+		}
+		bits := uint(strconv.IntSize)
+		if kind != reflect.Int {
+			bits = 8 << uint(kind-reflect.Int8)
+		}
+		return bits == 64 || (x >= -1<<(bits-1) && x < 1<<(bits-1))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, exact := constant.Uint64Val(constant.ToInt(v))
+		if !exact {
+			return false
+		}
+		bits := uint(strconv.IntSize)
+		if kind >= reflect.Uint8 && kind <= reflect.Uint64 {
+			bits = 8 << uint(kind-reflect.Uint8)
+		}
+		return bits == 64 || x < 1<<bits
+	case reflect.Float32, reflect.Float64:
+		x := constant.ToFloat(v)
+		if x.Kind() != constant.Float {
+			return false
+		}
+		f, _ := constant.Float64Val(x)
+		if kind == reflect.Float32 {
+			return math.Abs(f) <= math.MaxFloat32
+		}
+		return !math.IsInf(f, 0)
+	case reflect.Complex64, reflect.Complex128:
+		return constant.ToComplex(v).Kind() == constant.Complex
+	}
+	return false
+}
+
+// getTypeName returns a name of the type including type arguments, like "LRU[string,*github.com/x/models.User]"
+func getTypeName(it *item) string {
+	if len(it.args) == 0 {
+		return it.name
+	}
+	return fmt.Sprintf("%s[%s]", it.name, strings.Join(it.args, ","))
+}
+
+// getTypeArgs returns the type arguments of the item using aliases of imports, like "[string, *p2.User]"
+func getTypeArgs(imp imports, it *item) (string, error) {
+	if len(it.args) == 0 {
+		return "", nil
 	}
-	// deserialize items
-	if _, err := os.Stat(typesPath); err == nil {
-		types, err := ioutil.ReadFile(typesPath)
+	args := []string{}
+	for _, arg := range it.args {
+		expr, err := getTypeExpr(arg, func(path string) string {
+			return string(appendImport(imp, path))
+		})
 		if err != nil {
-			return nil, err
+			return "", err
 		}
-		var info []typeInfo
-		dec := gob.NewDecoder(bytes.NewReader(types))
-		if err := dec.Decode(&info); err != nil {
-			return nil, err
+		args = append(args, expr)
+	}
+	return fmt.Sprintf("[%s]", strings.Join(args, ", ")), nil
+}
+
+// getTypeExpr converts the type name in the format of the reflect package to the type expression
+// using the aliases of imports, like "map[string]*github.com/x/models.User" to "map[string]*p2.User"
+func getTypeExpr(name string, alias func(path string) string) (string, error) {
+	var prefix string
+	switch {
+	case strings.HasPrefix(name, "*"):
+		prefix, name = "*", name[1:]
+	case strings.HasPrefix(name, "[]"):
+		prefix, name = "[]", name[2:]
+	case strings.HasPrefix(name, "map["):
+		end := findTypeEnd(name, 3)
+		if end < 0 {
+			return "", fmt.Errorf(TypeDoesNotSupportedF, name)
+		}
+		key, err := getTypeExpr(name[4:end], alias)
+		if err != nil {
+			return "", err
+		}
+		prefix, name = fmt.Sprintf("map[%s]", key), name[end+1:]
+	case strings.HasPrefix(name, "["):
+		end := strings.Index(name, "]")
+		if end < 0 {
+			return "", fmt.Errorf(TypeDoesNotSupportedF, name)
+		}
+		prefix, name = name[:end+1], name[end+1:]
+	}
+	if prefix != "" {
+		expr, err := getTypeExpr(name, alias)
+		if err != nil {
+			return "", err
+		}
+		return prefix + expr, nil
+	}
+	if name == "" || strings.ContainsAny(name, " ({") {
+		// the func, chan, struct and interface types are not supported
+		return "", fmt.Errorf(TypeDoesNotSupportedF, name)
+	}
+	// it is a named type with optional type arguments
+	args := ""
+	if pos := strings.Index(name, "["); pos > -1 {
+		if !strings.HasSuffix(name, "]") {
+			return "", fmt.Errorf(TypeDoesNotSupportedF, name)
+		}
+		list := []string{}
+		for _, arg := range splitTypeArgs(name[pos+1 : len(name)-1]) {
+			expr, err := getTypeExpr(arg, alias)
+			if err != nil {
+				return "", err
+			}
+			list = append(list, expr)
+		}
+		name, args = name[:pos], fmt.Sprintf("[%s]", strings.Join(list, ", "))
+	}
+	if pos := strings.LastIndex(name, "."); pos > -1 {
+		if a := alias(name[:pos]); a != "" {
+			name = a + name[pos:]
 		} else {
-			return info, nil
+			name = name[pos+1:]
+		}
+	}
+	return name + args, nil
+}
+
+// findTypeEnd returns a position of the closing bracket for the opening bracket at the pos
+func findTypeEnd(input string, pos int) int {
+	depth := 0
+	for i := pos; i < len(input); i++ {
+		switch input[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTypeArgs splits the type arguments by commas skipping the nested brackets
+func splitTypeArgs(input string) []string {
+	res := []string{}
+	depth := 0
+	beg := 0
+	for i := 0; i < len(input); i++ {
+		switch input[i] {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				res = append(res, strings.TrimSpace(input[beg:i]))
+				beg = i + 1
+			}
+		}
+	}
+	return append(res, strings.TrimSpace(input[beg:]))
+}
+
+// getTypeArgsName returns a part of the function name for type arguments,
+// the pointers, slices, arrays and maps are prefixed to keep names unique,
+// the types of the qualified packages are named by the full import path
+func getTypeArgsName(name string, qualified map[string]bool) string {
+	switch {
+	case strings.HasPrefix(name, "*"):
+		return "Ptr" + getTypeArgsName(name[1:], qualified)
+	case strings.HasPrefix(name, "[]"):
+		return "Slice" + getTypeArgsName(name[2:], qualified)
+	case strings.HasPrefix(name, "map["):
+		if end := findTypeEnd(name, 3); end > -1 {
+			return "Map" + getTypeArgsName(name[4:end], qualified) + getTypeArgsName(name[end+1:], qualified)
 		}
+	case strings.HasPrefix(name, "["):
+		if end := strings.Index(name, "]"); end > -1 {
+			return "Array" + name[1:end] + getTypeArgsName(name[end+1:], qualified)
+		}
+	}
+	args := ""
+	if pos := strings.Index(name, "["); pos > -1 && strings.HasSuffix(name, "]") {
+		for _, arg := range splitTypeArgs(name[pos+1 : len(name)-1]) {
+			args += getTypeArgsName(arg, qualified)
+		}
+		name = name[:pos]
+	}
+	title := cases.Title(language.English, cases.NoLower)
+	if pos := strings.LastIndex(name, "."); pos > -1 && qualified[name[:pos]] {
+		name = getPathName(name[:pos]) + name[pos+1:]
+	} else if pos > -1 {
+		name = title.String(filepath.Base(name[:pos])) + name[pos+1:]
+	}
+	return title.String(name) + args
+}
+
+// getPathName returns the import path as a part of the function name,
+// like "github.com/x/models" to "GithubComXModels"
+func getPathName(path string) string {
+	title := cases.Title(language.English, cases.NoLower)
+	name := ""
+	for _, s := range strings.FieldsFunc(path, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		name += title.String(s)
+	}
+	return name
+}
+
+// getUnderlying returns the field with the element and the key types of its named type
+func getUnderlying(types []typeInfo, f *field) *field {
+	if f.Elem != nil || f.PkgPath == "" {
+		return f
+	}
+	info := getType(types, f.Id)
+	if info == nil || info.Elem == nil {
+		return f
+	}
+	res := *f
+	res.Elem = info.Elem
+	res.Key = info.Key
+	res.Len = info.Len
+	return &res
+}
+
+func getKindName(kind uint) string {
+	switch kind {
+	case itemKind.Func:
+		return "func"
+	case itemKind.Struct:
+		return "struct"
+	case itemKind.String:
+		return "string"
+	case itemKind.Number:
+		return "number"
+	case itemKind.Boolean:
+		return "boolean"
+	case itemKind.Inline:
+		return "inline"
+	case itemKind.Slice:
+		return "slice"
+	case itemKind.Map:
+		return "map"
+	case itemKind.Env:
+		return "env"
+	case itemKind.Value:
+		return "value"
+	case itemKind.Collection:
+		return "collection"
+	default:
+		return "none"
+	}
+}
+
+func getTypeId(it *item) string {
+	// the id of the generic type includes type arguments
+	if len(it.args) > 0 {
+		return strings.TrimPrefix(it.path, "*") + it.pkg + "." + getTypeName(it)
+	}
+	id := it.original
+	// remove the group name from the original
+	if it.group != "" {
+		id = id[len(it.group)+2:]
+	}
+	// remove the prefix of the value item
+	if it.kind == itemKind.Value {
+		id = strings.TrimPrefix(id, "=")
+	}
+	// remove the body of the inline item
+	if it.kind == itemKind.Inline {
+		id = strings.TrimSpace(id[:strings.Index(id, "{")])
+	}
+	return strings.TrimPrefix(id, "*")
+}
+
+// getRefItem returns the reference to the struct item
+func getRefItem(it item) item {
+	ref := it
+	ref.ref = true
+	ref.path = "*" + it.path
+	if it.group == "" {
+		ref.original = "*" + it.original
+	} else {
+		ref.original = fmt.Sprintf("[%s]*%s", it.group, it.original[len(it.group)+2:])
+	}
+	return ref
+}
+
+// getFuncInfo returns the signature of the package-level function,
+// it is nil for methods, type conversions and generic functions with inferred type arguments
+func getFuncInfo(types []typeInfo, it *item) *field {
+	if it.path+it.pkg == "" {
+		return nil
+	}
+	info := getType(types, getFuncId(it))
+	if info == nil || info.Value == nil || info.Value.Kind != reflect.Func {
+		return nil
 	}
-	return nil, errors.New(ErrorOnGettingTypeDetails)
+	return info.Value
+}
+
+// isFallibleFunc returns true if the function returns a value and an error
+func isFallibleFunc(sig *field) bool {
+	return len(sig.Out) == 2 && sig.Out[1].Id == errorTypeId
+}
+
+// getLifecycle returns the methods of the type like "Start(context.Context) error",
+// "Stop(context.Context) error" and "Close() error", it is nil if the type has none of them
+func getLifecycle(types []typeInfo, id string) *lifecycle {
+	t := getType(types, id)
+	if t == nil {
+		return nil
+	}
+	res := lifecycle{}
+	for _, m := range t.Methods {
+		in := m.In
+		// the receiver is the first input parameter of the methods except interfaces
+		if t.Kind != reflect.Interface && len(in) > 0 {
+			in = in[1:]
+		}
+		if m.Variadic || len(m.Out) != 1 || m.Out[0].Id != errorTypeId {
+			continue
+		}
+		withContext := len(in) == 1 && in[0].Id == contextTypeId
+		switch {
+		case m.Name == "Start" && withContext:
+			res.start = true
+		case m.Name == "Stop" && withContext:
+			res.stop = true
+		case m.Name == "Close" && len(in) == 0:
+			res.close = true
+		default:
+			continue
+		}
+		res.pointer = res.pointer || m.Pointer
+	}
+	if !res.start && !res.stop && !res.close {
+		return nil
+	}
+	return &res
+}
+
+// getPointerMethod returns a name of the method of the interface which is declared with the pointer receiver by the type,
+// it is empty if the value of the type has all methods of the interface
+func getPointerMethod(types []typeInfo, iface *field, id string) string {
+	infoA := getType(types, iface.Id)
+	infoB := getType(types, id)
+	if infoA == nil || infoB == nil || infoA.Kind != reflect.Interface || infoB.Kind == reflect.Interface {
+		return ""
+	}
+	for _, m := range infoA.Methods {
+		for _, x := range infoB.Methods {
+			if x.Name == m.Name && x.Pointer {
+				return x.Name
+			}
+		}
+	}
+	return ""
+}
+
+// getResultLifecycle returns the lifecycle methods of the first result of the function
+func getResultLifecycle(types []typeInfo, sig *field) *lifecycle {
+	if sig == nil || len(sig.Out) == 0 {
+		return nil
+	}
+	res := sig.Out[0]
+	if res.Kind == reflect.Ptr && res.Id == "." && res.Elem != nil {
+		res = *res.Elem
+	}
+	return getLifecycle(types, res.Id)
+}
+
+// getComponentCode returns a statement which registers the variable to start and stop it together with the application
+func getComponentCode(lc *lifecycle, name string, v string) string {
+	code := fmt.Sprintf("\taddComponent(component{name: %s", strconv.Quote(name))
+	if lc.start {
+		code += fmt.Sprintf(", start: %s.Start", v)
+	}
+	if lc.stop {
+		code += fmt.Sprintf(", stop: %s.Stop", v)
+	}
+	if lc.close {
+		code += fmt.Sprintf(", close: %s.Close", v)
+	}
+	return code + "})\n"
+}
+
+// getSharedCode wraps the body of the "Use" function to create the instance only once
+func getSharedCode(imp imports, funcName, typeName string, fallible bool, code []string) []string {
+	varName := strings.ToLower(funcName[:1]) + funcName[1:]
+	res := []string{}
+	res = append(res, fmt.Sprintf("var %sOnce %s.Once\n", varName, appendImport(imp, "sync")))
+	if fallible {
+		// the error is kept to return it for all dependencies
+		res = append(res, fmt.Sprintf("var %s %s\n", varName, typeName))
+		res = append(res, fmt.Sprintf("var %sErr error\n\n", varName))
+		res = append(res, code[0])
+		res = append(res, fmt.Sprintf("\t%sOnce.Do(func() {\n", varName))
+		res = append(res, fmt.Sprintf("\t\t%s, %sErr = func() (%s, error) {\n", varName, varName, typeName))
+		for _, line := range code[1 : len(code)-1] {
+			res = append(res, "\t\t"+line)
+		}
+		res = append(res, "\t\t}()\n")
+		res = append(res, "\t})\n")
+		res = append(res, fmt.Sprintf("\treturn %s, %sErr\n", varName, varName))
+		res = append(res, "}\n\n")
+		return res
+	}
+	res = append(res, fmt.Sprintf("var %s %s\n\n", varName, typeName))
+	res = append(res, code[0])
+	res = append(res, fmt.Sprintf("\t%sOnce.Do(func() {\n", varName))
+	// the last lines return the instance
+	for _, line := range code[1 : len(code)-2] {
+		res = append(res, "\t"+line)
+	}
+	res = append(res, fmt.Sprintf("\t\t%s = v\n", varName))
+	res = append(res, "\t})\n")
+	res = append(res, fmt.Sprintf("\treturn %s\n", varName))
+	res = append(res, "}\n\n")
+	return res
+}
+
+// getFuncId returns an id of the package-level function including type arguments
+func getFuncId(it *item) string {
+	return strings.TrimPrefix(it.path, "*") + it.pkg + "." + getTypeName(it)
 }
 
 func getFieldInfo(types []typeInfo, item string, field string) (*field, error) {
+	path, err := getFieldPath(types, item, field)
+	if err != nil {
+		return nil, err
+	}
+	return &path[len(path)-1], nil
+}
+
+// getFieldPath returns the embedded fields which promote the field and the field itself,
+// the shallowest field is selected like Go does
+func getFieldPath(types []typeInfo, item string, name string) ([]field, error) {
 	item = strings.TrimPrefix(item, "*")
 	info := getType(types, item)
 	if info == nil {
 		return nil, fmt.Errorf(TypeIsMissingF, item)
 	}
-	for _, v := range info.Fields {
-		if v.FieldName == field {
-			return &v, nil
+	type level struct {
+		info *typeInfo
+		path []field
+	}
+	done := map[string]bool{info.Id: true}
+	curr := []level{{info, nil}}
+	for len(curr) > 0 {
+		found := [][]field{}
+		next := []level{}
+		for _, x := range curr {
+			for _, f := range x.info.Fields {
+				path := append(append([]field{}, x.path...), f)
+				if f.FieldName == name {
+					found = append(found, path)
+				}
+				if !f.Embedded {
+					continue
+				}
+				// the embedded struct can be a pointer
+				id := f.Id
+				if f.Kind == reflect.Ptr && f.Elem != nil {
+					id = f.Elem.Id
+				}
+				// the type embedded at a shallower depth shadows it, the same type at the same depth is kept to detect ambiguity
+				if embedded := getType(types, id); embedded != nil && embedded.Kind == reflect.Struct && !done[id] {
+					next = append(next, level{embedded, path})
+				}
+			}
+		}
+		for _, x := range next {
+			done[x.info.Id] = true
+		}
+		switch len(found) {
+		case 0:
+			curr = next
+		case 1:
+			return found[0], nil
+		default:
+			return nil, fmt.Errorf(FieldIsAmbiguousF, name, item)
 		}
 	}
-	return nil, fmt.Errorf(FieldIsMissingF, field, item)
+	return nil, fmt.Errorf(FieldIsMissingF, name, item)
+}
+
+// getTypeDefine returns the type declaration of the field
+func getTypeDefine(imp imports, f *field) (string, error) {
+	if strings.Contains(f.TypeName, "[") {
+		// it is an instantiated generic type
+		return getTypeExpr(f.PkgPath+"."+f.TypeName, func(path string) string {
+			return string(appendImport(imp, path))
+		})
+	}
+	if f.TypeName != "" {
+		alias := string(appendImport(imp, f.PkgPath))
+		if alias == "" {
+			return f.TypeName, nil
+		} else {
+			return fmt.Sprintf("%s.%s", alias, f.TypeName), nil
+		}
+	}
+	switch f.Kind {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Pointer:
+		if f.Elem == nil || (f.Kind == reflect.Map && f.Key == nil) {
+			break
+		}
+		elem, err := getTypeDefine(imp, f.Elem)
+		if err != nil {
+			return "", err
+		}
+		switch f.Kind {
+		case reflect.Array:
+			return fmt.Sprintf("[%d]%s", f.Len, elem), nil
+		case reflect.Slice:
+			return "[]" + elem, nil
+		case reflect.Map:
+			key, err := getTypeDefine(imp, f.Key)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("map[%s]%s", key, elem), nil
+		default:
+			return "*" + elem, nil
+		}
+	case reflect.Chan:
+		if f.Elem == nil {
+			break
+		}
+		elem, err := getTypeDefine(imp, f.Elem)
+		if err != nil {
+			return "", err
+		}
+		switch f.ChanDir {
+		case reflect.SendDir:
+			return "chan<- " + elem, nil
+		case reflect.RecvDir:
+			return "<-chan " + elem, nil
+		default:
+			return "chan " + elem, nil
+		}
+	case reflect.Interface:
+		return "interface{}", nil
+	case reflect.Func:
+		params := [2][]string{}
+		for i, list := range [2][]field{f.In, f.Out} {
+			for _, p := range list {
+				t, err := getTypeDefine(imp, &p)
+				if err != nil {
+					return "", err
+				}
+				params[i] = append(params[i], t)
+			}
+		}
+		if n := len(params[0]); f.Variadic && n > 0 {
+			params[0][n-1] = "..." + strings.TrimPrefix(params[0][n-1], "[]")
+		}
+		code := fmt.Sprintf("func(%s)", strings.Join(params[0], ", "))
+		switch len(params[1]) {
+		case 0:
+			return code, nil
+		case 1:
+			return fmt.Sprintf("%s %s", code, params[1][0]), nil
+		default:
+			return fmt.Sprintf("%s (%s)", code, strings.Join(params[1], ", ")), nil
+		}
+	}
+	return "", fmt.Errorf(TypeDoesNotSupportedF, f.Kind)
+}
+
+// getTypeDesc returns a description of the field type qualified by the package path, like "chan<- []net/http.Header"
+func getTypeDesc(f *field) string {
+	if f.TypeName != "" {
+		if f.PkgPath == "" {
+			return f.TypeName
+		}
+		return f.PkgPath + "." + f.TypeName
+	}
+	elem := ""
+	if f.Elem != nil {
+		elem = getTypeDesc(f.Elem)
+	}
+	switch f.Kind {
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", f.Len, elem)
+	case reflect.Slice:
+		return "[]" + elem
+	case reflect.Pointer:
+		return "*" + elem
+	case reflect.Map:
+		if f.Key != nil {
+			return fmt.Sprintf("map[%s]%s", getTypeDesc(f.Key), elem)
+		}
+	case reflect.Chan:
+		switch f.ChanDir {
+		case reflect.SendDir:
+			return "chan<- " + elem
+		case reflect.RecvDir:
+			return "<-chan " + elem
+		default:
+			return "chan " + elem
+		}
+	case reflect.Interface:
+		return "interface {}"
+	case reflect.Func:
+		params := [2][]string{}
+		for i, list := range [2][]field{f.In, f.Out} {
+			for _, p := range list {
+				params[i] = append(params[i], getTypeDesc(&p))
+			}
+		}
+		if n := len(params[0]); f.Variadic && n > 0 {
+			params[0][n-1] = "..." + strings.TrimPrefix(params[0][n-1], "[]")
+		}
+		code := fmt.Sprintf("func(%s)", strings.Join(params[0], ", "))
+		switch len(params[1]) {
+		case 0:
+			return code
+		case 1:
+			return fmt.Sprintf("%s %s", code, params[1][0])
+		default:
+			return fmt.Sprintf("%s (%s)", code, strings.Join(params[1], ", "))
+		}
+	}
+	return f.Kind.String()
+}
+
+// getItemDefine returns a type of the struct item, like "*p1.Field2"
+func getItemDefine(imp imports, it *item) (string, error) {
+	args, err := getTypeArgs(imp, it)
+	if err != nil {
+		return "", err
+	}
+	if len(it.path) == 0 {
+		return it.name + args, nil
+	}
+	if it.path[0] == '*' {
+		return fmt.Sprintf("*%s.%s%s", appendImport(imp, it.path[1:]+it.pkg), it.name, args), nil
+	}
+	return fmt.Sprintf("%s.%s%s", appendImport(imp, it.path+it.pkg), it.name, args), nil
 }
 
 func getFuncName(it *item, ref bool) string {
@@ -303,7 +1185,16 @@ func getFuncName(it *item, ref bool) string {
 		group = it.group + GenGroupPrefix
 	}
 	name := fmt.Sprintf("%s%s%s%s", GenNamePrefix, group, cases.Title(language.English, cases.NoLower).String(it.pkg), it.name)
-	name = strings.ReplaceAll(name, "-", "_")
+	if len(it.args) > 0 {
+		name += GenTypeArgsPrefix
+		for _, arg := range it.args {
+			name += getTypeArgsName(arg, it.qualified)
+		}
+	}
+	name = strings.NewReplacer("-", "_", ".", "_").Replace(name)
+	if it.kind == itemKind.Inline {
+		name = fmt.Sprintf("%s%s%d", name, GenInlineSufix, it.inline)
+	}
 	if ref {
 		name = name + GenRefSufix
 	}
@@ -352,22 +1243,6 @@ func isModExist() (bool, error) {
 	return checkMod(wd)
 }
 
-func isDebugging() bool {
-	pid := os.Getppid()
-	// We loop in case there were intermediary processes like the gopls language server.
-	for pid != 0 {
-		p, err := ps.FindProcess(pid)
-		if err != nil || p == nil {
-			return false
-		}
-		if p.Executable() == "dlv.exe" {
-			return true
-		}
-		pid = p.PPid()
-	}
-	return false
-}
-
 func goMod(wd string, name string) ([]byte, error) {
 	// if the module mode is disabled then exit
 	if strings.ToLower(os.Getenv("GO111MODULE")) == "off" {
@@ -406,21 +1281,6 @@ func goMod(wd string, name string) ([]byte, error) {
 	return out, err
 }
 
-func goRun(src string) ([]byte, error) {
-	args := []string{"run", src}
-	cmd := exec.Command("go", args...)
-	if isDebugging() {
-		// resolve the debugging sb application
-		cmd.Dir, _ = filepath.Abs(filepath.Dir(os.Args[0]))
-	}
-	out, err := cmd.Output()
-	if e, ok := err.(*exec.ExitError); ok {
-		return out, fmt.Errorf("%s", e.Stderr)
-	} else {
-		return out, err
-	}
-}
-
 func goBuild(src, dst string) error {
 	args := []string{"build"}
 	if dst != "" {
@@ -477,123 +1337,3 @@ func checkApplication(application string) error {
 	}
 	return nil
 }
-
-func genSerializeType(id int, imp string, x typeInfo) string {
-	return fmt.Sprintf("\tvar v%d %s.%s\n", id, imp, x.Name) +
-		fmt.Sprintf("\tdata = append(data, getType(&v%d))", id)
-}
-
-func genSerializeFunc(filePath string) string {
-	return `type Field struct {
-	Id        string
-	Kind      reflect.Kind
-	TypeName  string
-	FieldName string
-	PkgPath   string
-}
-
-type Method struct {
-	Name string
-	In   []Field
-	Out  []Field
-}
-
-type Type struct {
-	Id      string
-	Kind    reflect.Kind
-	Name    string
-	String  string
-	PkgPath string
-	Fields  []Field
-	Methods []Method
-}
-
-func getType(v interface{}) Type {
-	e := reflect.TypeOf(v).Elem()
-	info := Type{
-		Id:      fmt.Sprintf("%s.%s", e.PkgPath(), e.Name()),
-		Kind:    e.Kind(),
-		Name:    e.Name(),
-		String:  e.String(),
-		PkgPath: e.PkgPath(),
-	}
-	if e.Kind() == reflect.Struct {
-		info.Fields = getFields(e)
-		info.Methods = getMethods(reflect.TypeOf(v))
-	} else if e.Kind() == reflect.Interface {
-		info.Methods = getMethods(e)
-	}
-	return info
-}
-
-func getFields(t reflect.Type) []Field {
-	res := []Field{}
-	var f reflect.StructField
-	for i := 0; i < t.NumField(); i++ {
-		f = t.Field(i)
-		res = append(res, Field{
-			Id:        fmt.Sprintf("%s.%s", f.Type.PkgPath(), f.Type.Name()),
-			Kind:      f.Type.Kind(),
-			TypeName:  f.Type.Name(),
-			FieldName: f.Name,
-			PkgPath:   f.Type.PkgPath(),
-		})
-	}
-	return res
-}
-
-func getMethods(t reflect.Type) []Method {
-	res := []Method{}
-	var x Method
-	var m reflect.Method
-	for i := 0; i < t.NumMethod(); i++ {
-		m = t.Method(i)
-		x = Method{Name: m.Name}
-		// input params
-		for n := 0; n < m.Type.NumIn(); n++ {
-			ti := m.Type.In(n)
-			x.In = append(x.In, Field{
-				Id:        fmt.Sprintf("%s.%s", ti.PkgPath(), ti.Name()),
-				Kind:      ti.Kind(),
-				TypeName:  ti.Name(),
-				FieldName: ti.Name(),
-				PkgPath:   ti.PkgPath(),
-			})
-		}
-		// output params
-		for n := 0; n < m.Type.NumOut(); n++ {
-			to := m.Type.Out(n)
-			x.Out = append(x.Out, Field{
-				Id:        fmt.Sprintf("%s.%s", to.PkgPath(), to.Name()),
-				Kind:      to.Kind(),
-				TypeName:  to.Name(),
-				FieldName: to.Name(),
-				PkgPath:   to.PkgPath(),
-			})
-		}
-		res = append(res, x)
-	}
-	return res
-}
-
-func serialize(info []Type) {
-	var buf bytes.Buffer
-	enc := gob.NewEncoder(&buf)
-	err := enc.Encode(info)
-	if err == nil {
-		file, err := os.Create("` + strings.ReplaceAll(filePath, "\\", "\\\\") + `")
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer func() {
-			file.Close()
-		}()
-		writer := bufio.NewWriter(file)
-		writer.Write(buf.Bytes())
-		writer.Flush()
-	} else {
-		fmt.Println(err)
-	}
-}`
-}
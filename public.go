@@ -5,7 +5,30 @@ package sgo
 
 type Coder struct {
 	Logger Logger
-	items  map[string][][]string
+	// Profile selects the group items which override the ungrouped items,
+	// it takes precedence over the SGO_PROFILE environment variable and the "profile" attribute of the application
+	Profile string
+	items   map[string][][]string
+	// the root folder of the cached type details, the user cache folder is used if it is empty
+	cacheDir string
+	// the version of Go which is detected once
+	goVersion string
+}
+
+// Report is a result of analysis of all applications
+type Report struct {
+	// Unused are the items which are not used by any application
+	Unused []string
+	// Unresolved are the applications which entry cannot be resolved
+	Unresolved []UnresolvedApp
+}
+
+type UnresolvedApp struct {
+	Application string
+	Entry       string
+	// Profile is the profile which the application cannot be resolved with
+	Profile string
+	Error   string
 }
 
 type Builder struct {
@@ -27,11 +50,21 @@ type Logger interface {
 
 const (
 	// application
-	GenNamePrefix   string = "Use"
-	GenGroupPrefix  string = "Group"
-	GenRefSufix     string = "Ref"
-	GenAdapterSufix string = "Adapter"
+	GenNamePrefix     string = "Use"
+	GenGroupPrefix    string = "Group"
+	GenRefSufix       string = "Ref"
+	GenAdapterSufix   string = "Adapter"
+	GenInlineSufix    string = "Inline"
+	GenEnvPrefix      string = "Env"
+	GenTypeArgsPrefix string = "Of"
+	// graph formats
+	GraphFormatDot     string = "dot"
+	GraphFormatMermaid string = "mermaid"
+	GraphFormatJson    string = "json"
 	// notifications
+	ItemIsUnusedF           string = "the \"%s\" item is not used by any application"
+	AppIsUnresolvedF        string = "the \"%s\" application cannot be resolved: %s"
+	AppProfileIsUnresolvedF string = "the \"%s\" application cannot be resolved with the \"%s\" profile: %s"
 	// errors
 	AppIsMissingF                        string = "the selected \"%s\" application does not found"
 	AppIsNotSpecified                    string = "the application is not specified"
@@ -44,7 +77,15 @@ const (
 	ItemIsMissingF                       string = "the %s item is not found"
 	ItemIsIncorrect                      string = "cannot detect type of \"%s\" item"
 	FieldIsMissingF                      string = "\"%s\" field of \"%s\" type does not exist"
+	ProviderErrorIsMissingF              string = "the creation of \"%s\" item can fail, the \"%s\" field should be a function returning an error"
+	FuncArgsAreIncorrectF                string = "the %s function call has %d arguments, but %s are expected"
+	FuncArgIsIncorrectF                  string = "the argument %d of %s function call is incorrect: %w"
+	ComponentIsCopiedF                   string = "the %s component is started or stopped by the methods with the pointer receiver, but it is created by value"
+	FuncIsIncompatibleF                  string = "the %s function cannot be used as \"%s\" type"
+	FuncResultIsIncompatibleF            string = "the result of %s function call cannot be used as \"%s\" type"
+	FieldIsAmbiguousF                    string = "\"%s\" field of \"%s\" type is promoted by several embedded fields"
 	MethodIsMissingF                     string = "the \"%s\" method is missing in \"%s\""
+	MethodHasPointerReceiverF            string = "the \"%s\" method has the pointer receiver in \"%s\", the reference should be used"
 	ParamsDoesNotSupportedF              string = "cannot resolve \"%s\" and \"%s\" parameters"
 	BuilderFileDoesNotExistF             string = "\"%s\" does not exist. Please use a \"code\" command to create it"
 	WrongNumberOfInputParamsF            string = "the number of input parameters are different for \"%s\" method of \"%s\" type and \"%s\" type"
@@ -54,5 +95,27 @@ const (
 	ErrorOnGettingTypeDetails            string = "cannot collect type details"
 	FuncBegTokenIsMissing                string = "incorrect syntax, the \"(\" is missing"
 	FuncEndTokenIsMissing                string = "incorrect syntax, the \")\" is missing"
+	FuncParamIsMissingF                  string = "the parameter %d of the function is missing"
 	GroupEndTokenIsMissing               string = "cannot get a group name, the \"]\" is missing"
+	InlineBegTokenIsMissing              string = "incorrect syntax, the \"{\" is missing"
+	InlineFieldValueIsMissingF           string = "the value of \"%s\" field is missing"
+	SliceElementIsMissingF               string = "the element %d of the slice is missing"
+	SliceIsOutOfRangeF                   string = "the number of elements is out of range of \"%s\" array"
+	MapSeparatorIsMissingF               string = "incorrect syntax, the \":\" is missing after \"%s\" key"
+	MapValueIsMissingF                   string = "the value of \"%s\" key is missing"
+	MapKeyIsDuplicatedF                  string = "the \"%s\" key is duplicated"
+	ValueIsIncompatibleF                 string = "the %s value cannot be used as \"%s\" type"
+	EnvParamsAreIncorrectF               string = "the %s item should have the name and the default value of the environment variable"
+	DurationIsIncorrectF                 string = "the %s value is not a valid duration"
+	ProviderIsIncorrectF                 string = "the \"%s\" field should be a function without parameters returning the \"%s\" item and an optional error"
+	GraphFormatIsUnknownF                string = "the \"%s\" graph format is unknown"
+	BaseItemIsMissingF                   string = "the \"%s\" base item of \"%s\" item is missing"
+	ScopeIsIncorrectF                    string = "the \"%s\" scope of \"%s\" item is incorrect"
+	DependencyCycleIsDetectedF           string = "the dependency cycle is detected: %s"
+	ImplementationIsAmbiguousF           string = "the \"%s\" field of \"%s\" item has several implementations: %s"
+	TypeArgsAreIncorrectF                string = "the type arguments of \"%s\" item are incorrect"
+	SyntaxErrorF                         string = "%s at column %d of \"%s\""
+	QuoteEndTokenIsMissing               string = "incorrect syntax, the closing quote is missing"
+	BracketEndTokenIsMissingF            string = "incorrect syntax, the \"%c\" is missing"
+	TokenIsUnexpectedF                   string = "incorrect syntax, the \"%c\" is unexpected"
 )
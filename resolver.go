@@ -4,6 +4,10 @@ package sgo
 
 import (
 	"fmt"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -12,12 +16,38 @@ func (r *resolver) resolve(wd string) (items, []typeInfo, error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	info, err := getCompiler().getTypeInfo(items, wd)
+	info, err := r.getTypeInfo(r.getKnownItems(items), wd)
 	if err != nil {
 		return nil, nil, err
-	} else {
-		return items, info, nil
 	}
+	// bind the interface fields until all of them are processed
+	for r.autowire {
+		wired, err := r.wire(items, info)
+		if err != nil {
+			return nil, nil, err
+		} else if !wired {
+			break
+		}
+		if err = r.checkCycles(items); err != nil {
+			return nil, nil, err
+		}
+		// the bound items can depend on items of new types
+		if !hasTypeInfo(items, info) {
+			if info, err = r.getTypeInfo(r.getKnownItems(items), wd); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return items, info, nil
+}
+
+// getTypeInfo returns the details of the types of the items,
+// the packages are loaded once if the loader is shared by resolvers
+func (r *resolver) getTypeInfo(list items, wd string) ([]typeInfo, error) {
+	if r.loader != nil {
+		return getCompiler().loadTypeInfo(list, r.loader)
+	}
+	return getCompiler().getTypeInfo(list, wd, r.cache)
 }
 
 func (r *resolver) getItems() (list items, err error) {
@@ -31,6 +61,13 @@ func (r *resolver) getItems() (list items, err error) {
 }
 
 func (r *resolver) getItem(itemName string, list items) (*item, error) {
+	// the group item of the profile overrides the ungrouped item
+	if r.profile != "" && !strings.HasPrefix(itemName, "[") {
+		groupItemName := fmt.Sprintf("[%s]%s", r.profile, strings.TrimPrefix(itemName, "*"))
+		if _, found := r.items[groupItemName]; found {
+			itemName = fmt.Sprintf("[%s]%s", r.profile, itemName)
+		}
+	}
 	if it, found := list[itemName]; found {
 		return &it, nil
 	}
@@ -52,11 +89,29 @@ func (r *resolver) getItem(itemName string, list items) (*item, error) {
 	//var err error
 	var deps [][]string
 	groupItemName := ""
-	if it.group == "" {
-		deps = r.items[simpleItemName]
-	} else {
+	if it.group != "" {
 		groupItemName = fmt.Sprintf("[%s]%s", it.group, simpleItemName)
-		deps = r.items[groupItemName]
+	}
+	// check the dependency cycle
+	if err := r.enter(simpleItemName, groupItemName); err != nil {
+		return nil, err
+	}
+	defer r.leave()
+	if it.kind == itemKind.Inline {
+		// the inline item declares all dependencies itself
+		r.inlines++
+		it.inline = r.inlines
+		deps, err = getParser().parseInline(it.original)
+		if err != nil {
+			return nil, err
+		}
+	} else if it.group == "" {
+		deps, err = r.getDeps(simpleItemName, simpleItemName, nil)
+	} else {
+		deps, err = r.getDeps(groupItemName, simpleItemName, nil)
+	}
+	if err != nil {
+		return nil, err
 	}
 	var k, v string
 	var l int
@@ -71,6 +126,19 @@ func (r *resolver) getItem(itemName string, list items) (*item, error) {
 		} else {
 			v = ""
 		}
+		// the scope attribute is not a dependency
+		if k == scopeAttrName {
+			switch v {
+			case singletonScope:
+				it.singleton = true
+			case transientScope:
+				it.singleton = false
+			default:
+				return nil, fmt.Errorf(ScopeIsIncorrectF, v, itemName)
+			}
+			continue
+		}
+		r.stack[len(r.stack)-1].field = k
 		refIt, err = r.getItem(v, list)
 		if err != nil {
 			return nil, err
@@ -78,8 +146,9 @@ func (r *resolver) getItem(itemName string, list items) (*item, error) {
 			it.deps = append(it.deps, dep{k, refIt})
 		}
 	}
+	r.stack[len(r.stack)-1].field = ""
 	// process the input parameters for functions
-	if it.kind == itemKind.Func {
+	if it.kind == itemKind.Func || it.kind == itemKind.Env {
 		params, err := getParser().parseFunc(it.original)
 		if err != nil {
 			return nil, err
@@ -94,15 +163,310 @@ func (r *resolver) getItem(itemName string, list items) (*item, error) {
 			}
 		}
 	}
-	// add a simple item to the result set
-	if groupItemName == "" {
-		list[simpleItemName] = it
-	} else {
-		list[groupItemName] = it
+	// the environment variable has the name and the default value
+	if it.kind == itemKind.Env {
+		count := len(it.deps)
+		if count < 1 || count > 2 || it.deps[0].item.kind != itemKind.String {
+			return nil, fmt.Errorf(EnvParamsAreIncorrectF, itemName)
+		}
+		for _, d := range it.deps[1:] {
+			switch d.item.kind {
+			case itemKind.String, itemKind.Number, itemKind.Boolean:
+			default:
+				return nil, fmt.Errorf(EnvParamsAreIncorrectF, itemName)
+			}
+		}
+	}
+	// process the elements of slices
+	if it.kind == itemKind.Slice {
+		elems, err := getParser().parseSlice(it.original)
+		if err != nil {
+			return nil, err
+		}
+		for i, elem := range elems {
+			refIt, err = r.getItem(elem, list)
+			if err != nil {
+				return nil, err
+			} else if refIt != nil {
+				it.deps = append(it.deps, dep{strconv.Itoa(i), refIt})
+			}
+		}
+	}
+	// process the values of maps
+	if it.kind == itemKind.Map {
+		rows, err := getParser().parseMap(it.original)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			refIt, err = r.getItem(row[1], list)
+			if err != nil {
+				return nil, err
+			} else if refIt != nil {
+				it.deps = append(it.deps, dep{row[0], refIt})
+			}
+		}
 	}
-	// if the original item is ref item then add it too
+	// process all items of the group
+	if it.kind == itemKind.Collection {
+		for i, name := range r.getGroupItems(it.group) {
+			if it.ref {
+				name = "*" + name
+			}
+			refIt, err = r.getItem(fmt.Sprintf("[%s]%s", it.group, name), list)
+			if err != nil {
+				return nil, err
+			} else if refIt != nil {
+				it.deps = append(it.deps, dep{strconv.Itoa(i), refIt})
+			}
+		}
+	}
+	// add a simple item to the result set,
+	// the ref item is added by the original name to keep the simple item of the same type
 	if it.ref {
 		list[itemName] = it
+	} else if groupItemName == "" {
+		list[simpleItemName] = it
+	} else {
+		list[groupItemName] = it
 	}
 	return &it, nil
 }
+
+// getGroupItems returns the sorted names of all items of the group without the group name
+func (r *resolver) getGroupItems(group string) []string {
+	prefix := fmt.Sprintf("[%s]", group)
+	names := []string{}
+	for name := range r.items {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name[len(prefix):])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// enter adds the item to the stack of items in progress or returns an error if the item is in progress already
+func (r *resolver) enter(simpleItemName, groupItemName string) error {
+	name := simpleItemName
+	if groupItemName != "" {
+		name = groupItemName
+	}
+	for i, x := range r.stack {
+		if x.name == name {
+			cycle := []string{}
+			for _, f := range r.stack[i:] {
+				if f.field == "" {
+					cycle = append(cycle, f.name)
+				} else {
+					cycle = append(cycle, fmt.Sprintf("%s.%s", f.name, f.field))
+				}
+			}
+			cycle = append(cycle, name)
+			return fmt.Errorf(DependencyCycleIsDetectedF, strings.Join(cycle, " → "))
+		}
+	}
+	r.stack = append(r.stack, frame{name: name})
+	return nil
+}
+
+// leave removes the last item from the stack of items in progress
+func (r *resolver) leave() {
+	r.stack = r.stack[:len(r.stack)-1]
+}
+
+// getDeps returns the dependencies of the item merged with the dependencies of the base item,
+// the ungrouped item is used as a base item if the name of the base item is not specified
+func (r *resolver) getDeps(itemName, simpleItemName string, chain []string) ([][]string, error) {
+	deps := r.items[itemName]
+	if r.used != nil {
+		r.used[itemName] = true
+	}
+	base := ""
+	extends := false
+	for _, n := range deps {
+		if len(n) > 0 && n[0] == extendsAttrName {
+			extends = true
+			if len(n) > 1 && n[1] != "" {
+				base = strings.TrimPrefix(n[1], "*")
+			} else {
+				base = simpleItemName
+			}
+		}
+	}
+	if !extends {
+		return deps, nil
+	}
+	if _, found := r.items[base]; !found {
+		return nil, fmt.Errorf(BaseItemIsMissingF, base, itemName)
+	}
+	chain = append(chain, itemName)
+	for i, x := range chain {
+		if x == base {
+			return nil, fmt.Errorf(DependencyCycleIsDetectedF, strings.Join(append(chain[i:], base), " → "))
+		}
+	}
+	baseDeps, err := r.getDeps(base, simpleItemName, chain)
+	if err != nil {
+		return nil, err
+	}
+	// override the dependencies of the base item, the method calls are appended only
+	res := [][]string{}
+	index := map[string]int{}
+	for _, n := range baseDeps {
+		if len(n) > 0 && n[0] != "." {
+			index[n[0]] = len(res)
+		}
+		res = append(res, n)
+	}
+	for _, n := range deps {
+		if len(n) == 0 || n[0] == extendsAttrName {
+			continue
+		}
+		if i, found := index[n[0]]; found && n[0] != "." {
+			res[i] = n
+		} else {
+			index[n[0]] = len(res)
+			res = append(res, n)
+		}
+	}
+	return res, nil
+}
+
+// getKnownItems returns the resolved items and the candidates to autowire
+func (r *resolver) getKnownItems(list items) items {
+	if !r.autowire {
+		return list
+	}
+	known := items{}
+	for name, it := range r.getCandidates(list) {
+		known[name] = it
+	}
+	for name, it := range list {
+		known[name] = it
+	}
+	return known
+}
+
+// getCandidates returns the ungrouped struct items which are declared or resolved
+func (r *resolver) getCandidates(list items) map[string]item {
+	candidates := map[string]item{}
+	names := []string{}
+	for name := range r.items {
+		names = append(names, name)
+	}
+	for name := range list {
+		names = append(names, strings.TrimPrefix(name, "*"))
+	}
+	for _, name := range names {
+		if it, err := getParser().parseItem(name); err == nil && it.kind == itemKind.Struct && it.group == "" && it.pkg != "" {
+			candidates[name] = it
+		}
+	}
+	return candidates
+}
+
+// wire binds the interface fields which are not declared to the unique struct item implementing the interface,
+// it returns true if any field is bound
+func (r *resolver) wire(list items, types []typeInfo) (bool, error) {
+	candidates := r.getCandidates(list)
+	keys := []string{}
+	for name := range candidates {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	names := []string{}
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	wired := false
+	adapter := adapter{}
+	for _, name := range names {
+		it := list[name]
+		if it.kind != itemKind.Struct && it.kind != itemKind.Inline {
+			continue
+		}
+		id := getTypeId(&it)
+		info := getType(types, id)
+		if info == nil {
+			return false, fmt.Errorf(TypeIsMissingF, id)
+		}
+		declared := map[string]bool{}
+		for _, d := range it.deps {
+			declared[d.name] = true
+		}
+		for _, f := range info.Fields {
+			// the declared fields and the fields of type interface{} or error are skipped
+			if f.Kind != reflect.Interface || f.PkgPath == "" || declared[f.FieldName] || !token.IsExported(f.FieldName) {
+				continue
+			}
+			found := []string{}
+			for _, key := range keys {
+				c := candidates[key]
+				if getTypeId(&c) == id {
+					continue
+				}
+				if supported, err := adapter.areTypesCompatible(types, f, getTypeId(&c)); err == nil && supported {
+					found = append(found, key)
+				}
+			}
+			if len(found) == 0 {
+				continue
+			} else if len(found) > 1 {
+				return false, fmt.Errorf(ImplementationIsAmbiguousF, f.FieldName, name, strings.Join(found, ", "))
+			}
+			// the reference implements all methods of the struct
+			refIt, err := r.getItem("*"+found[0], list)
+			if err != nil {
+				return false, err
+			}
+			it.deps = append(it.deps, dep{f.FieldName, refIt})
+			list[name] = it
+			wired = true
+		}
+	}
+	return wired, nil
+}
+
+// checkCycles returns an error if the resolved items depend on themselves
+func (r *resolver) checkCycles(list items) error {
+	names := []string{}
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	done := map[string]bool{}
+	for _, name := range names {
+		if err := r.findCycle(list, name, []frame{}, done); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findCycle returns an error with a path of the dependency cycle which passes through the item
+func (r *resolver) findCycle(list items, name string, path []frame, done map[string]bool) error {
+	if done[name] {
+		return nil
+	}
+	for i, x := range path {
+		if x.name == name {
+			cycle := []string{}
+			for _, f := range path[i:] {
+				cycle = append(cycle, fmt.Sprintf("%s.%s", f.name, f.field))
+			}
+			cycle = append(cycle, name)
+			return fmt.Errorf(DependencyCycleIsDetectedF, strings.Join(cycle, " → "))
+		}
+	}
+	if it, found := list[name]; found {
+		for _, d := range it.deps {
+			if err := r.findCycle(list, d.item.original, append(path, frame{name, d.name}), done); err != nil {
+				return err
+			}
+		}
+	}
+	done[name] = true
+	return nil
+}
@@ -1,8 +1,15 @@
 package test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
 
+	"github.com/hashicorp/go-hclog"
 	"github.com/nanomarkup/sgo"
 	"github.com/spf13/cobra"
 )
@@ -13,6 +20,34 @@ type Runner interface {
 
 type RunnerImpl struct{}
 
+type RunnerImpl2 struct{}
+
+type Runnable interface {
+	Run()
+}
+
+type Greeter interface {
+	Greet(runner Runner)
+}
+
+type GreeterImpl struct{}
+
+type Handler interface {
+	Handle(runner Runner)
+}
+
+type Processor struct {
+	Name string
+}
+
+type Notifier interface {
+	Notify(runner Runner, events chan<- string, names ...string) []string
+}
+
+type NotifierImpl struct{}
+
+type NotifierImpl2 struct{}
+
 type Item1 struct {
 	Int1      int
 	Bool1     bool
@@ -23,13 +58,90 @@ type Item1 struct {
 	Field2V2  Field2
 	Field2Ref *Field2
 	Field3    Field3
+	Field4    *Field4
+	Names     []string
+	Ports     [3]int
+	Fields    []*Field2
+	Runners   []Runner
+	Matrix    [][]float64
+	Labels    map[string]string
+	Weights   map[int]float64
+	Handlers  map[string]Runner
+	Settings  map[string]interface{}
+	DSN       string
+	Port      uint16
+	Debug     bool
+	Timeout   time.Duration
+	Ratio     float64
+	Value     interface{}
+	Level     hclog.Level
+	MinLevel  hclog.Level
+	HttpPort  Port
+	Mode      Mode
+	Modes     []Mode
+	Aliases   Names
+	Levels    Levels
+	Output    io.Writer
+	File      *os.File
+	Args      []string
+	Cache     Cache[string, *Field2]
+	Cache2    Cache[int, Field2]
+	Pair      *Pair[int, Cache[string, []string]]
+	Greeter   Greeter
+	Greeters  []Greeter
+	Lazy      func() Field2
+	Provider  func() *Field2
+	Factory   func() (Runner, error)
+	Greeters2 []func() Greeter
 	Runner    Runner
 	Logger    sgo.Logger
 	Hello     func(string)
 	EmptyFunc func()
 	Cmd       *cobra.Command
+	Server    *http.Server
+	Notifier  Notifier
+	Events    chan<- string
+	Updates   <-chan string
+	Format    func(string, ...interface{}) string
+	Service   *Service
+	Dual      Dual
+	DB        *Database
+	Repo      *Repository
+	Repos     []*Repository
+	RepoFunc  func() (*Repository, error)
+	RepoLazy  func() *Repository
+	Worker    *Worker
+	Workers   func() *Worker
+	Job       Worker
+	Handler   Handler
+	Small     int16
+	Twins     Twins
+}
+
+type Port int
+
+type Mode string
+
+type Names []string
+
+type Levels map[Mode]hclog.Level
+
+type Cache[K comparable, V any] struct {
+	Items map[K]V
+	Size  int
 }
 
+type Pair[A any, B any] struct {
+	First  A
+	Second B
+}
+
+var Events = make(chan string)
+
+var Total int64 = 5
+
+var Updates <-chan string = Events
+
 type Field1 struct{}
 
 type Field2 struct {
@@ -40,6 +152,56 @@ type Field3 struct {
 	Field Field1
 }
 
+type BaseConfig struct {
+	Timeout time.Duration
+	Host    string
+}
+
+type Service struct {
+	BaseConfig
+	*http.Server
+	RunnerImpl
+	Name string
+}
+
+type Database struct {
+	DSN string
+}
+
+type Worker struct {
+	DB   *Database
+	Name string
+}
+
+type Repository struct {
+	DB *Database
+}
+
+type Dual struct {
+	Field2
+	Field4
+}
+
+type Left struct {
+	BaseConfig
+}
+
+type Right struct {
+	BaseConfig
+}
+
+type Twins struct {
+	Left
+	Right
+}
+
+type Field4 struct {
+	Name   string
+	Port   int
+	Field  Field2
+	Runner Runner
+}
+
 func NewField1() Field1 {
 	return Field1{}
 }
@@ -56,6 +218,25 @@ func NewField3(field Field1) Field3 {
 	return Field3{field}
 }
 
+func NewDatabase(dsn string) (*Database, error) {
+	if dsn == "" {
+		return nil, errors.New("the DSN is empty")
+	}
+	return &Database{DSN: dsn}, nil
+}
+
+func NewJob(name string) Worker {
+	return Worker{Name: name}
+}
+
+func NewService(name string, port Port, runners ...Runner) *Service {
+	return &Service{Name: name}
+}
+
+func NewCache[K comparable, V any](size int) Cache[K, V] {
+	return Cache[K, V]{map[K]V{}, size}
+}
+
 func Hello(name string) {
 	fmt.Printf("Hello %s!", name)
 }
@@ -72,8 +253,64 @@ func (r *RunnerImpl) Run() {
 
 }
 
-func (i *Item1) Execute() {
+func (r *RunnerImpl2) Run() {
+
+}
+
+func (g *GreeterImpl) Greet(runner Runnable) {
+
+}
+
+func (n *NotifierImpl) Notify(runner Runnable, events chan<- string, names ...string) []string {
+	return names
+}
+
+func (n *NotifierImpl2) Notify(runner Runnable, events chan string, names ...string) []string {
+	return names
+}
+
+func (d *Database) Close() error {
+	fmt.Printf("close %s\n", d.DSN)
+	return nil
+}
 
+func (w *Worker) Start(ctx context.Context) error {
+	fmt.Printf("start %s\n", w.Name)
+	return nil
+}
+
+func (w *Worker) Stop(ctx context.Context) error {
+	fmt.Printf("stop %s\n", w.Name)
+	return nil
+}
+
+func (p *Processor) Start(ctx context.Context) error {
+	fmt.Printf("start %p\n", p)
+	return nil
+}
+
+func (p *Processor) Stop(ctx context.Context) error {
+	fmt.Printf("stop %p\n", p)
+	return nil
+}
+
+func (p *Processor) Handle(runner Runnable) {
+	fmt.Printf("handle %p\n", p)
+}
+
+func (i *Item1) Execute() {
+	if i.Workers != nil {
+		i.Workers()
+	}
+	if i.Handler != nil {
+		i.Handler.Handle(&RunnerImpl{})
+	}
+	// the singletons are shared by all consumers
+	if i.Field2Ref != nil && len(i.Fields) == 2 && len(i.Greeters) == 2 {
+		fmt.Printf("shared %t\n", i.Field2Ref == i.Fields[0] && i.Fields[0] == i.Fields[1] &&
+			i.Greeter == i.Greeters[0] && i.Greeters[0] == i.Greeters[1])
+	}
+	fmt.Println("execute")
 }
 
 func CmdCobra(cmd *cobra.Command, args []string) error {
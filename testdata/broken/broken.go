@@ -0,0 +1,12 @@
+package broken
+
+import "fmt"
+
+type Broken struct {
+	Name  string
+	Value Undefined
+}
+
+func Hello() string {
+	return fmt.Sprint("hello")
+}
@@ -0,0 +1,18 @@
+package users
+
+import (
+	"fmt"
+
+	"github.com/nanomarkup/sgo/test"
+	a "github.com/nanomarkup/sgo/testdata/a/models"
+	b "github.com/nanomarkup/sgo/testdata/b/models"
+)
+
+type Users struct {
+	Admins   test.Cache[string, *a.User]
+	Visitors test.Cache[string, *b.User]
+}
+
+func (u *Users) Execute() {
+	fmt.Println(u.Admins.Size, u.Visitors.Size)
+}